@@ -0,0 +1,219 @@
+package main
+
+import (
+	"html"
+	"html/template"
+	"strings"
+)
+
+// bbcodeRenderer converts the BBCode markup used by the class reference XML to HTML.
+type bbcodeRenderer struct {
+	set       *xmlClassSet
+	className string
+}
+
+func newBBCodeRenderer(set *xmlClassSet, className string) *bbcodeRenderer {
+	return &bbcodeRenderer{set: set, className: className}
+}
+
+// memberTags are the tags that reference a class member, e.g. [method Node.add_child].
+var memberTags = map[string]struct{}{
+	"method":      {},
+	"member":      {},
+	"signal":      {},
+	"constant":    {},
+	"enum":        {},
+	"annotation":  {},
+	"theme_item":  {},
+	"constructor": {},
+	"operator":    {},
+}
+
+// simpleTags map directly to an HTML element.
+var simpleTags = map[string]string{
+	"b":   "b",
+	"i":   "i",
+	"u":   "u",
+	"s":   "s",
+	"kbd": "kbd",
+}
+
+// codeTags contain text that is rendered verbatim, up to the matching closing tag.
+var codeTags = map[string]string{
+	"code":      "",
+	"codeblock": "",
+	"gdscript":  "gdscript",
+	"csharp":    "csharp",
+}
+
+// render returns s as HTML. Lines outside of code blocks are rendered as paragraphs.
+func (r *bbcodeRenderer) render(s string) template.HTML {
+	s = dedent(s)
+	if s == "" {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("<p>")
+	for len(s) > 0 {
+		i := strings.IndexAny(s, "[\n")
+		if i < 0 {
+			sb.WriteString(html.EscapeString(s))
+			break
+		}
+		sb.WriteString(html.EscapeString(s[:i]))
+		if s[i] == '\n' {
+			sb.WriteString("</p>\n<p>")
+			s = strings.TrimLeft(s[i+1:], " \t")
+			continue
+		}
+
+		end := strings.IndexByte(s[i:], ']')
+		if end < 0 {
+			sb.WriteString(html.EscapeString(s[i:]))
+			break
+		}
+		tag := s[i+1 : i+end]
+		s = s[i+end+1:]
+		s = r.renderTag(&sb, tag, s)
+	}
+	sb.WriteString("</p>")
+
+	out := strings.ReplaceAll(sb.String(), "<p></p>", "")
+	return template.HTML(strings.TrimSpace(out))
+}
+
+// renderTag writes the HTML for tag to sb and returns the remaining input, which is advanced
+// past the closing tag for tags with verbatim content.
+func (r *bbcodeRenderer) renderTag(sb *strings.Builder, tag, rest string) string {
+	name, arg, _ := strings.Cut(tag, " ")
+	if n, v, ok := strings.Cut(name, "="); ok {
+		name, arg = n, v
+	}
+
+	if lang, ok := codeTags[name]; ok {
+		code, after, ok := strings.Cut(rest, "[/"+name+"]")
+		if !ok {
+			// without its closing tag, the rest of the text is not code
+			sb.WriteString(html.EscapeString("[" + tag + "]"))
+			return rest
+		}
+		if name == "code" {
+			sb.WriteString("<code>" + html.EscapeString(code) + "</code>")
+			return after
+		}
+		code = strings.Trim(dedent(code), "\n")
+		sb.WriteString("</p>\n<pre")
+		if lang != "" {
+			sb.WriteString(` class="highlight-` + lang + `"`)
+		}
+		sb.WriteString("><code>" + html.EscapeString(code) + "</code></pre>\n<p>")
+		return strings.TrimLeft(after, "\n")
+	}
+
+	switch {
+	case name == "codeblocks" || name == "/codeblocks":
+		// container for the [gdscript] and [csharp] code blocks
+		return rest
+	case name == "br":
+		sb.WriteString("<br>")
+		return rest
+	case name == "param":
+		sb.WriteString("<code>" + html.EscapeString(arg) + "</code>")
+		return rest
+	case name == "url":
+		if arg == "" {
+			url, after, ok := strings.Cut(rest, "[/url]")
+			if !ok {
+				break
+			}
+			sb.WriteString(`<a class="reference external" href="` + html.EscapeString(url) + `">` + html.EscapeString(url) + "</a>")
+			return after
+		}
+		sb.WriteString(`<a class="reference external" href="` + html.EscapeString(arg) + `">`)
+		return rest
+	case name == "/url":
+		sb.WriteString("</a>")
+		return rest
+	}
+
+	if el, ok := simpleTags[name]; ok {
+		sb.WriteString("<" + el + ">")
+		return rest
+	}
+	if el, ok := simpleTags[strings.TrimPrefix(name, "/")]; ok && strings.HasPrefix(name, "/") {
+		sb.WriteString("</" + el + ">")
+		return rest
+	}
+
+	if _, ok := memberTags[name]; ok && arg != "" {
+		r.writeMemberRef(sb, arg)
+		return rest
+	}
+
+	if arg == "" && isClassName(name) {
+		if r.set != nil {
+			if ref := r.set.ref(name); ref.Href != "" {
+				sb.WriteString(`<a class="reference internal" href="` + ref.Href + `"><code>` + html.EscapeString(name) + "</code></a>")
+				return rest
+			}
+		}
+		// a class that is not documented by the XML, such as Variant
+		sb.WriteString("<code>" + html.EscapeString(name) + "</code>")
+		return rest
+	}
+
+	// not BBCode, such as an array literal in the text
+	sb.WriteString(html.EscapeString("[" + tag + "]"))
+	return rest
+}
+
+// isClassName reports whether s looks like a class reference, e.g. [Node] or [@GlobalScope].
+func isClassName(s string) bool {
+	s = strings.TrimPrefix(s, "@")
+	if s == "" || s[0] < 'A' || s[0] > 'Z' {
+		return false
+	}
+	return strings.IndexFunc(s, func(r rune) bool {
+		return !(r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z')
+	}) < 0
+}
+
+// writeMemberRef writes a reference to a member, linking to the page of its class
+// when the reference is qualified, e.g. Node.add_child.
+func (r *bbcodeRenderer) writeMemberRef(sb *strings.Builder, ref string) {
+	code := "<code>" + html.EscapeString(ref) + "</code>"
+	if r.set != nil {
+		if class, _, ok := strings.Cut(ref, "."); ok && class != r.className {
+			if cref := r.set.ref(class); cref.Href != "" {
+				sb.WriteString(`<a class="reference internal" href="` + cref.Href + `">` + code + "</a>")
+				return
+			}
+		}
+	}
+	sb.WriteString(code)
+}
+
+// dedent removes the indentation shared by all non-empty lines of s, which comes from the
+// nesting of the XML elements, and trims surrounding blank lines.
+func dedent(s string) string {
+	lines := strings.Split(s, "\n")
+	indent := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		n := len(line) - len(strings.TrimLeft(line, "\t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	for i, line := range lines {
+		if len(line) >= indent && indent > 0 {
+			lines[i] = line[indent:]
+		} else if strings.TrimSpace(line) == "" {
+			lines[i] = ""
+		}
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
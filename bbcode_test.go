@@ -0,0 +1,91 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	r := newBBCodeRenderer(newXMLClassSet([]xmlClass{{Name: "Node"}, {Name: "Sprite2D"}}), "Sprite2D")
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"empty", "\n\t\t", ""},
+		{"paragraphs", "\n\t\tFirst line.\n\t\tSecond line.\n\t", "<p>First line.</p>\n<p>Second line.</p>"},
+		{"escaped", "a < b & c", "<p>a &lt; b &amp; c</p>"},
+		{"simple tags", "[b]bold[/b] [i]italic[/i] [kbd]Ctrl[/kbd]", "<p><b>bold</b> <i>italic</i> <kbd>Ctrl</kbd></p>"},
+		{"class", "A [Node] and a [Variant].", `<p>A <a class="reference internal" href="class_node.html"><code>Node</code></a> and a <code>Variant</code>.</p>`},
+		{"member of another class", "[method Node.add_child]", `<p><a class="reference internal" href="class_node.html"><code>Node.add_child</code></a></p>`},
+		{"member of the class", "[member Sprite2D.texture] and [signal ready]", "<p><code>Sprite2D.texture</code> and <code>ready</code></p>"},
+		{"param", "[param node]", "<p><code>node</code></p>"},
+		{"code", "[code][1, 2][/code]", "<p><code>[1, 2]</code></p>"},
+		{"codeblock", "Example:\n[codeblock]\n\tvar x = 1\n\tif x:\n\t\tpass\n[/codeblock]\nDone.",
+			"<p>Example:</p>\n\n<pre><code>var x = 1\nif x:\n\tpass</code></pre>\n<p>Done.</p>"},
+		{"code samples", "[codeblocks]\n[gdscript]\nprint(1)\n[/gdscript]\n[csharp]\nGD.Print(1);\n[/csharp]\n[/codeblocks]",
+			"<pre class=\"highlight-gdscript\"><code>print(1)</code></pre>\n\n<pre class=\"highlight-csharp\"><code>GD.Print(1);</code></pre>"},
+		{"url", "[url]https://godotengine.org[/url] [url=https://docs.godotengine.org]docs[/url]",
+			`<p><a class="reference external" href="https://godotengine.org">https://godotengine.org</a> <a class="reference external" href="https://docs.godotengine.org">docs</a></p>`},
+		{"array literal", "Returns [1, 2].", "<p>Returns [1, 2].</p>"},
+		{"unclosed bracket", "Returns [1, 2", "<p>Returns [1, 2</p>"},
+		// the text after an unclosed tag is rendered as usual
+		{"unclosed code", "Use [code]x and [b]y[/b].", "<p>Use [code]x and <b>y</b>.</p>"},
+		{"unclosed codeblock", "[codeblock]\nvar x\nA [Node].", `<p>[codeblock]</p>` + "\n" + `<p>var x</p>` + "\n" + `<p>A <a class="reference internal" href="class_node.html"><code>Node</code></a>.</p>`},
+		{"unclosed url", "See [url]https://godotengine.org", "<p>See [url]https://godotengine.org</p>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(r.render(tt.in)); got != tt.want {
+				t.Errorf("render(%q) =\n%s\nwant\n%s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDedent(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"empty", "", ""},
+		{"no indentation", "a\nb", "a\nb"},
+		{"shared indentation", "\n\t\ta\n\t\t\tb\n\t", "a\n\tb"},
+		{"blank lines", "\t\ta\n\n\t\t\n\t\tb", "a\n\n\nb"},
+		{"least indented line", "\t\t\ta\n\tb", "\t\ta\nb"},
+		{"spaces are kept", "\t  a\n\tb", "  a\nb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dedent(tt.in); got != tt.want {
+				t.Errorf("dedent(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsClassName(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"Node", true},
+		{"Node2D", true},
+		{"@GlobalScope", true},
+		{"AESContext", true},
+		{"Some_Class", true},
+		{"", false},
+		{"@", false},
+		{"node", false},
+		{"b", false},
+		{"/b", false},
+		{"1, 2", false},
+		{"Node.add_child", false},
+		{"Array[int]", false},
+	}
+	for _, tt := range tests {
+		if got := isClassName(tt.in); got != tt.want {
+			t.Errorf("isClassName(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
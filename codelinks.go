@@ -0,0 +1,154 @@
+package main
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	css "github.com/andybalholm/cascadia"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// codeIndex maps the identifiers of the class reference, class names and qualified member
+// names such as Input.is_action_pressed, to the page and anchor documenting them.
+type codeIndex map[string]string
+
+// newCodeIndex returns the identifiers of the classes of the docset and their members.
+// Members are only known by their qualified names, as a bare method name, such as
+// add_child, could belong to any class.
+func newCodeIndex() codeIndex {
+	idx := make(codeIndex)
+	for class, docPath := range hierarchy.paths {
+		idx[class] = docPath
+		for _, row := range hierarchy.members[class] {
+			name, _, _ := strings.Cut(row.Name, "(")
+			name = strings.TrimSpace(name)
+			href := row.Member.DocPath + "#" + row.Member.Target
+			idx[class+"."+name] = href
+			// the values of an enum are also referenced through the class, e.g.
+			// Node.PROCESS_MODE_INHERIT for Node.ProcessMode.PROCESS_MODE_INHERIT
+			if _, value, ok := strings.Cut(name, "."); ok && row.Type == "Enum" {
+				if _, exists := idx[class+"."+value]; !exists {
+					idx[class+"."+value] = href
+				}
+			}
+		}
+	}
+	return idx
+}
+
+var (
+	selInlineCode = css.MustCompile("code")
+	selCodeBlocks = css.MustCompile("pre")
+	// codeIdentifier matches inline code that is an identifier, optionally called, e.g.
+	// Input.is_action_pressed("jump").
+	codeIdentifier = regexp.MustCompile(`^(@?[A-Za-z_]\w*(?:\.[A-Za-z_]\w*)*)(?:\(.*\))?$`)
+)
+
+// codeLinker links the identifiers in the code of a guide to the class reference.
+type codeLinker struct {
+	index   codeIndex
+	dir     string // dir is the directory of the guide, which links are relative to
+	limit   int
+	written int
+}
+
+// linkCode links the class names and qualified member names in the inline code and code
+// blocks of the guide at docPath to their documentation, adding at most limit links.
+// Identifiers are only linked when they match a class or member exactly, and never in
+// comments, strings or existing links.
+func linkCode(doc *goquery.Document, docPath string, index codeIndex, limit int) int {
+	l := &codeLinker{index: index, dir: filepath.Dir(docPath), limit: limit}
+
+	doc.FindMatcher(selInlineCode).EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		if s.Closest("a, pre").Length() > 0 {
+			return true
+		}
+		m := codeIdentifier.FindStringSubmatch(strings.TrimSpace(s.Text()))
+		if m == nil {
+			return true
+		}
+		if href, ok := l.index[m[1]]; ok {
+			l.wrap(href, s.Get(0))
+		}
+		return l.written < l.limit
+	})
+
+	doc.FindMatcher(selCodeBlocks).EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		for n := s.Get(0).FirstChild; n != nil && l.written < l.limit; n = n.NextSibling {
+			name, ok := nameToken(n)
+			if !ok {
+				continue
+			}
+			// names without a dot can only be classes in the index
+			href, ok := l.index[name]
+			if !ok {
+				continue
+			}
+			// a member accessed through its class, e.g. Node.new or Input.is_action_pressed
+			nodes := []*html.Node{n}
+			if dot := nextElement(n); dot != nil && nodeText(dot) == "." {
+				if member, ok := nameToken(nextElement(dot)); ok {
+					if memberHref, ok := l.index[name+"."+member]; ok {
+						href = memberHref
+						nodes = append(nodes, dot, nextElement(dot))
+					}
+				}
+			}
+			n = l.wrap(href, nodes...)
+		}
+		return l.written < l.limit
+	})
+	return l.written
+}
+
+// wrap wraps nodes, which are consecutive siblings, in a link to href and returns the link.
+func (l *codeLinker) wrap(href string, nodes ...*html.Node) *html.Node {
+	docPath, fragment, _ := strings.Cut(href, "#")
+	rel, err := filepath.Rel(l.dir, docPath)
+	if err != nil {
+		rel = docPath
+	}
+	rel = filepath.ToSlash(rel)
+	if fragment != "" {
+		rel += "#" + fragment
+	}
+	a := &html.Node{
+		Type:     html.ElementNode,
+		DataAtom: atom.A,
+		Data:     atom.A.String(),
+		Attr: []html.Attribute{
+			{Key: "class", Val: "reference internal code-link"},
+			{Key: "href", Val: rel},
+		},
+	}
+	nodes[0].Parent.InsertBefore(a, nodes[0])
+	for _, n := range nodes {
+		n.Parent.RemoveChild(n)
+		a.AppendChild(n)
+	}
+	l.written++
+	return a
+}
+
+// nameToken returns the text of a Pygments name token, such as <span class="n">Node</span>.
+func nameToken(n *html.Node) (string, bool) {
+	if n == nil || n.DataAtom != atom.Span {
+		return "", false
+	}
+	class := attr(n, "class")
+	if !strings.HasPrefix(class, "n") || strings.Contains(class, " ") {
+		return "", false
+	}
+	return nodeText(n), true
+}
+
+// nextElement returns the next sibling of n, if it is an element.
+func nextElement(n *html.Node) *html.Node {
+	if n.NextSibling != nil && n.NextSibling.Type == html.ElementNode {
+		return n.NextSibling
+	}
+	return nil
+}
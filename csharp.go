@@ -0,0 +1,131 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// csharpSuffix is appended to the class in the menu description of C# aliases.
+const csharpSuffix = " (C#)"
+
+// csharpAliases returns a row for each class member in rows, named using the conventions of
+// the Godot C# API, e.g. add_child is AddChild and the ready signal is SignalName.Ready.
+func csharpAliases(rows []SearchIndex) []SearchIndex {
+	type enumKey struct{ class, enum string }
+
+	// the C# API appends Enum to the name of an enum that conflicts with a property,
+	// such as Node.ProcessModeEnum, and removes the prefix shared by the enum values
+	properties := make(map[string]struct{})
+	values := make(map[enumKey][]string)
+	for _, row := range rows {
+		if row.Member == nil {
+			continue
+		}
+		switch row.Type {
+		case "Property":
+			properties[row.Member.Class+"."+pascalCase(row.Name)] = struct{}{}
+		case "Enum":
+			if enum, value, ok := strings.Cut(row.Name, "."); ok {
+				k := enumKey{row.Member.Class, enum}
+				values[k] = append(values[k], value)
+			}
+		}
+	}
+	enumName := func(class, enum string) string {
+		if _, ok := properties[class+"."+enum]; ok {
+			return enum + "Enum"
+		}
+		return enum
+	}
+
+	var aliases []SearchIndex
+	for _, row := range rows {
+		if row.Member == nil {
+			continue
+		}
+		class := row.Member.Class
+
+		var name string
+		switch row.Type {
+		case "Method":
+			name, _, _ = strings.Cut(row.Name, "(")
+			name = pascalCase(strings.TrimSpace(name))
+		case "Property", "Constant":
+			name = pascalCase(row.Name)
+		case "Signal":
+			name = "SignalName." + pascalCase(row.Name)
+		case "Enum":
+			enum, value, ok := strings.Cut(row.Name, ".")
+			if !ok {
+				name = enumName(class, enum)
+				break
+			}
+			prefix := enumValuePrefix(values[enumKey{class, enum}])
+			name = enumName(class, enum) + "." + pascalCase(value[len(prefix):])
+		default:
+			continue
+		}
+
+		if name == "" || name == row.Name {
+			continue
+		}
+		aliases = append(aliases, row.alias(name, class+csharpSuffix))
+	}
+	return aliases
+}
+
+// pascalCase converts a snake_case or SCREAMING_SNAKE_CASE name to PascalCase, keeping a
+// leading underscore as used by virtual methods, e.g. _ready is _Ready.
+func pascalCase(name string) string {
+	var sb strings.Builder
+	if strings.HasPrefix(name, "_") {
+		sb.WriteByte('_')
+	}
+	for _, word := range strings.Split(name, "_") {
+		if word == "" {
+			continue
+		}
+		word = strings.ToLower(word)
+		// dimensions are upper case, e.g. Position2D
+		if len(word) == 2 && unicode.IsDigit(rune(word[0])) && word[1] == 'd' {
+			sb.WriteString(strings.ToUpper(word))
+			continue
+		}
+		sb.WriteString(strings.ToUpper(word[:1]))
+		sb.WriteString(word[1:])
+	}
+	return sb.String()
+}
+
+// enumValuePrefix returns the prefix, up to and including an underscore, shared by all the
+// values of an enum, e.g. PROCESS_MODE_ for PROCESS_MODE_INHERIT and PROCESS_MODE_ALWAYS.
+// The prefix is shortened if removing it would leave a value starting with a digit.
+func enumValuePrefix(values []string) string {
+	if len(values) < 2 {
+		return ""
+	}
+	words := strings.SplitAfter(values[0], "_")
+	n := len(words) - 1 // the last word is never part of the prefix
+	for _, v := range values[1:] {
+		w := strings.SplitAfter(v, "_")
+		i := 0
+		for i < n && i < len(w)-1 && w[i] == words[i] {
+			i++
+		}
+		n = i
+	}
+	for ; n > 0; n-- {
+		prefix := strings.Join(words[:n], "")
+		ok := true
+		for _, v := range values {
+			if rest := v[len(prefix):]; rest == "" || unicode.IsDigit(rune(rest[0])) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return prefix
+		}
+	}
+	return ""
+}
@@ -0,0 +1,514 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"github.com/stuartcarnie/godotdash/pkg/parallel"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+var (
+	diffCmd = &cobra.Command{
+		Use:   "diff OLD NEW",
+		Short: "Report the API changes between two versions of the Godot documentation",
+		Long: `Report the classes and members that were added, removed, renamed or changed between two
+versions of the Godot documentation. OLD and NEW are godot-docs trees, docsets or docSet.dsidx
+files, optionally tagged with their version as VERSION=PATH.`,
+		Args: cobra.ExactArgs(2),
+		RunE: diffVersions,
+	}
+	// arguments
+	diffFormat string
+	diffBadges bool
+	diffLang   string
+)
+
+func init() {
+	diffCmd.Flags().StringVar(&diffFormat, "format", "markdown", "The format of the report: markdown or json")
+	diffCmd.Flags().BoolVar(&diffBadges, "badges", false, `Add "New in" badges to the class pages of the NEW docset and "Removed in" badges to those of the OLD docset`)
+	diffCmd.Flags().StringVar(&diffLang, "lang", "en", "The language of the documentation, e.g. fr or zh_CN")
+	cmd.AddCommand(diffCmd)
+}
+
+// The kinds of API changes.
+const (
+	changeAdded   = "added"
+	changeRemoved = "removed"
+	changeRenamed = "renamed" // same class, type and signature, but a different name
+	changeChanged = "changed" // same class, type and name, but a different signature
+)
+
+// apiEntry is a class or a class member of a search index.
+type apiEntry struct {
+	Class     string
+	Type      string // Type is the entry type of the member, or of the class for a class
+	Name      string // Name is the name of the member without its signature, or empty for a class
+	Signature string // Signature is the parameters and qualifiers of a method
+	DocPath   string // DocPath is the path of the class page
+	Target    string // Target is the dashtoc anchor of a member
+}
+
+func (e *apiEntry) String() string {
+	if e.Name == "" {
+		return e.Class
+	}
+	return e.Name + e.Signature
+}
+
+// apiVersion is the API of one of the versions being compared.
+type apiVersion struct {
+	Label     string
+	Documents string // Documents is the Documents directory of the docset, if the version is a docset
+	Entries   []apiEntry
+}
+
+// apiChange is a change to a class or class member. Renamed and changed members also
+// have the name and signature of the old version.
+type apiChange struct {
+	Change       string `json:"change"`
+	Class        string `json:"class"`
+	Type         string `json:"type"`
+	Name         string `json:"name,omitempty"`
+	Signature    string `json:"signature,omitempty"`
+	OldName      string `json:"old_name,omitempty"`
+	OldSignature string `json:"old_signature,omitempty"`
+
+	entry apiEntry // entry is the class or member in the version that is badged
+}
+
+func newAPIChange(change string, e apiEntry) apiChange {
+	return apiChange{
+		Change:    change,
+		Class:     e.Class,
+		Type:      e.Type,
+		Name:      e.Name,
+		Signature: e.Signature,
+		entry:     e,
+	}
+}
+
+// apiDiff is the report written by the diff command.
+type apiDiff struct {
+	Old     string      `json:"old"`
+	New     string      `json:"new"`
+	Changes []apiChange `json:"changes"`
+}
+
+func diffVersions(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if diffFormat != "markdown" && diffFormat != "json" {
+		return fmt.Errorf("unknown format %q, expected markdown or json", diffFormat)
+	}
+	oldIn, newIn := parseDocsInput(args[0]), parseDocsInput(args[1])
+	if diffBadges && newIn.Version == "" {
+		return errors.New("--badges requires the version of NEW, e.g. 4.3=" + newIn.Path)
+	}
+	if diffBadges {
+		// the docset indexed from a godot-docs tree is temporary
+		for _, in := range []docsInput{oldIn, newIn} {
+			if documentsPath(in.Path) == "" {
+				return fmt.Errorf("--badges requires docsets, %s is not a docset", in.Path)
+			}
+		}
+	}
+	l, err := lookupLocale(diffLang)
+	if err != nil {
+		return err
+	}
+	executor = parallel.NewExecutor()
+
+	oldAPI, err := readAPI(ctx, oldIn, l)
+	if err != nil {
+		return err
+	}
+	newAPI, err := readAPI(ctx, newIn, l)
+	if err != nil {
+		return err
+	}
+
+	d := &apiDiff{
+		Old:     oldAPI.Label,
+		New:     newAPI.Label,
+		Changes: diffAPI(oldAPI.Entries, newAPI.Entries),
+	}
+
+	if diffBadges {
+		if err = addBadges(newAPI, d.Changes, changeAdded, "New in "+newIn.Version); err != nil {
+			return err
+		}
+		if err = addBadges(oldAPI, d.Changes, changeRemoved, "Removed in "+newIn.Version); err != nil {
+			return err
+		}
+	}
+
+	if diffFormat == "json" {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "\t")
+		return enc.Encode(d)
+	}
+	return writeMarkdownDiff(cmd.OutOrStdout(), d)
+}
+
+// readAPI reads the classes and members of a docset, a docSet.dsidx file or a godot-docs
+// tree. A godot-docs tree is first indexed to a temporary docset, in the language l.
+func readAPI(ctx context.Context, in docsInput, l locale) (*apiVersion, error) {
+	v := &apiVersion{Label: in.Version}
+	if v.Label == "" {
+		v.Label = filepath.Base(filepath.Clean(in.Path))
+	}
+
+	var dbFilename string
+	switch {
+	case isFile(in.Path):
+		dbFilename = in.Path
+	case isFile(filepath.Join(in.Path, "Contents/Resources/docSet.dsidx")):
+		dbFilename = filepath.Join(in.Path, "Contents/Resources/docSet.dsidx")
+	case isFile(filepath.Join(in.Path, "classes/index.html")):
+		dir, err := os.MkdirTemp("", "godot-dash-diff-")
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = os.RemoveAll(dir) }()
+
+		cfg := buildConfig{DocsPath: in.Path, DocsetPath: dir, Version: in.Version, Lang: l}
+		if err = buildDocset(ctx, cfg); err != nil {
+			return nil, errors.Wrapf(err, "failed to index %s", in.Path)
+		}
+		dbFilename = filepath.Join(dir, "Contents/Resources/docSet.dsidx")
+	default:
+		return nil, fmt.Errorf("%s is not a godot-docs tree, docset or docSet.dsidx file", in.Path)
+	}
+
+	rows, err := readSearchIndex(dbFilename)
+	if err != nil {
+		return nil, err
+	}
+	v.Entries = apiEntries(rows)
+	v.Documents = documentsPath(in.Path)
+	return v, nil
+}
+
+// documentsPath returns the Documents directory of a docset or of the docset of a
+// docSet.dsidx file, or "" if path is neither.
+func documentsPath(path string) string {
+	docs := filepath.Join(path, "Contents/Resources/Documents")
+	if isFile(path) {
+		docs = filepath.Join(filepath.Dir(path), "Documents")
+	}
+	if !isDir(docs) {
+		return ""
+	}
+	return docs
+}
+
+func isFile(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.Mode().IsRegular()
+}
+
+func isDir(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.IsDir()
+}
+
+// readSearchIndex returns the rows of the search index of a docSet.dsidx file.
+func readSearchIndex(path string) ([]SearchIndex, error) {
+	// opening a missing database would create it
+	if !isFile(path) {
+		return nil, fmt.Errorf("database %s does not exist", path)
+	}
+	idx, err := gorm.Open(sqlite.Open(path), &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer func() {
+		if sqlDB, err := idx.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	}()
+
+	var rows []SearchIndex
+	if err = idx.Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return rows, nil
+}
+
+// searchIndexPath matches the path of the rows written by makeSearchIndexPath, capturing
+// the entry name, original name, menu description, document path and target.
+var searchIndexPath = regexp.MustCompile(`^<dash_entry_name=([^>]*)><dash_entry_originalName=([^>]*)><dash_entry_menuDescription=([^>]*)>([^#]*)#(.*)$`)
+
+// apiEntries returns the classes and members of the search index. Guides and the rows
+// derived from members are skipped.
+func apiEntries(rows []SearchIndex) []apiEntry {
+	var entries []apiEntry
+	for _, row := range rows {
+		if row.Type == "Guide" || row.Type == "Section" {
+			continue
+		}
+		m := searchIndexPath.FindStringSubmatch(row.Path)
+		if m == nil {
+			// the rows of classes link to the class page
+			if !strings.Contains(row.Path, "#") {
+				entries = append(entries, apiEntry{Class: row.Name, Type: row.Type, DocPath: row.Path})
+			}
+			continue
+		}
+		if m[1] != m[2] {
+			// the rows derived from a member, such as C# aliases, inherited members and
+			// qualified names, are named after the member
+			continue
+		}
+		class, _, _ := strings.Cut(m[3], statusSeparator)
+		if m[5] == "" {
+			// a deprecated or experimental class
+			entries = append(entries, apiEntry{Class: row.Name, Type: row.Type, DocPath: m[4]})
+			continue
+		}
+		name, sig, ok := strings.Cut(row.Name, "(")
+		if ok {
+			sig = "(" + sig
+		}
+		entries = append(entries, apiEntry{
+			Class:     class,
+			Type:      row.Type,
+			Name:      strings.TrimSpace(name),
+			Signature: strings.TrimSpace(sig),
+			DocPath:   m[4],
+			Target:    m[5],
+		})
+	}
+	return entries
+}
+
+// diffAPI returns the changes from old to new. The members of added and removed classes
+// are not reported separately.
+func diffAPI(old, new []apiEntry) []apiChange {
+	changes := make([]apiChange, 0)
+
+	isClass := func(e apiEntry) bool { return e.Name == "" }
+	oldClasses, oldMembers := lo.FilterReject(old, func(e apiEntry, _ int) bool { return isClass(e) })
+	newClasses, newMembers := lo.FilterReject(new, func(e apiEntry, _ int) bool { return isClass(e) })
+
+	_, removed, added := pairEntries(oldClasses, newClasses, func(e apiEntry) (string, bool) {
+		return e.Class, true
+	})
+	for _, e := range removed {
+		changes = append(changes, newAPIChange(changeRemoved, e))
+	}
+	for _, e := range added {
+		changes = append(changes, newAPIChange(changeAdded, e))
+	}
+
+	removedClasses := lo.SliceToMap(removed, func(e apiEntry) (string, struct{}) { return e.Class, struct{}{} })
+	addedClasses := lo.SliceToMap(added, func(e apiEntry) (string, struct{}) { return e.Class, struct{}{} })
+	oldMembers = lo.Reject(oldMembers, func(e apiEntry, _ int) bool {
+		_, ok := removedClasses[e.Class]
+		return ok
+	})
+	newMembers = lo.Reject(newMembers, func(e apiEntry, _ int) bool {
+		_, ok := addedClasses[e.Class]
+		return ok
+	})
+
+	// unchanged members
+	_, oldMembers, newMembers = pairEntries(oldMembers, newMembers, func(e apiEntry) (string, bool) {
+		return strings.Join([]string{e.Class, e.Type, e.Name, e.Signature}, "\x00"), true
+	})
+
+	var pairs []apiPair
+	pairs, oldMembers, newMembers = pairEntries(oldMembers, newMembers, func(e apiEntry) (string, bool) {
+		return strings.Join([]string{e.Class, e.Type, e.Name}, "\x00"), true
+	})
+	for _, p := range pairs {
+		c := newAPIChange(changeChanged, p.new)
+		c.OldSignature = p.old.Signature
+		changes = append(changes, c)
+	}
+
+	// only members with parameters have a signature distinctive enough to detect a rename
+	pairs, oldMembers, newMembers = pairEntries(oldMembers, newMembers, func(e apiEntry) (string, bool) {
+		return strings.Join([]string{e.Class, e.Type, e.Signature}, "\x00"), e.Signature != "" && !strings.HasPrefix(e.Signature, "()")
+	})
+	for _, p := range pairs {
+		c := newAPIChange(changeRenamed, p.new)
+		c.OldName = p.old.Name
+		changes = append(changes, c)
+	}
+
+	for _, e := range oldMembers {
+		changes = append(changes, newAPIChange(changeRemoved, e))
+	}
+	for _, e := range newMembers {
+		changes = append(changes, newAPIChange(changeAdded, e))
+	}
+
+	slices.SortFunc(changes, func(a, b apiChange) int {
+		return strings.Compare(
+			strings.Join([]string{a.Class, a.Name, a.Type, a.Signature, a.Change}, "\x00"),
+			strings.Join([]string{b.Class, b.Name, b.Type, b.Signature, b.Change}, "\x00"),
+		)
+	})
+	return changes
+}
+
+type apiPair struct{ old, new apiEntry }
+
+// pairEntries pairs the entries of old and new with the same key, and returns the entries
+// that were not paired. Entries without a key are never paired.
+func pairEntries(old, new []apiEntry, key func(e apiEntry) (string, bool)) (pairs []apiPair, oldRest, newRest []apiEntry) {
+	byKey := make(map[string][]int)
+	for i, e := range new {
+		if k, ok := key(e); ok {
+			byKey[k] = append(byKey[k], i)
+		}
+	}
+
+	paired := make([]bool, len(new))
+	for _, e := range old {
+		k, ok := key(e)
+		if idx := byKey[k]; ok && len(idx) > 0 {
+			pairs = append(pairs, apiPair{old: e, new: new[idx[0]]})
+			paired[idx[0]] = true
+			byKey[k] = idx[1:]
+			continue
+		}
+		oldRest = append(oldRest, e)
+	}
+	for i, e := range new {
+		if !paired[i] {
+			newRest = append(newRest, e)
+		}
+	}
+	return pairs, oldRest, newRest
+}
+
+// writeMarkdownDiff writes the report as a Markdown list of changes per class.
+func writeMarkdownDiff(w io.Writer, d *apiDiff) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# API changes from %s to %s\n", d.Old, d.New)
+	if len(d.Changes) == 0 {
+		sb.WriteString("\nNo changes.\n")
+	}
+
+	var class string
+	for _, c := range d.Changes {
+		if c.Class != class {
+			class = c.Class
+			fmt.Fprintf(&sb, "\n## %s\n\n", class)
+		}
+
+		what, name := "class", c.Class
+		if c.Name != "" {
+			what, name = strings.ToLower(c.Type), c.Name+c.Signature
+		}
+		switch c.Change {
+		case changeAdded:
+			fmt.Fprintf(&sb, "- Added %s `%s`\n", what, name)
+		case changeRemoved:
+			fmt.Fprintf(&sb, "- Removed %s `%s`\n", what, name)
+		case changeRenamed:
+			fmt.Fprintf(&sb, "- Renamed %s `%s` to `%s`\n", what, c.OldName, c.Name)
+		case changeChanged:
+			fmt.Fprintf(&sb, "- Changed %s `%s` to `%s`\n", what, c.Name+c.OldSignature, name)
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// addBadges adds a badge with text to the class pages of v for each change of the given
+// kind. Badges of a previous run are replaced.
+func addBadges(v *apiVersion, changes []apiChange, change, text string) error {
+	changes = lo.Filter(changes, func(c apiChange, _ int) bool { return c.Change == change })
+	if len(changes) == 0 {
+		return nil
+	}
+	if v.Documents == "" {
+		return fmt.Errorf("badges can only be added to a docset, %s is not a docset", v.Label)
+	}
+
+	pages := lo.GroupBy(changes, func(c apiChange) string { return c.entry.DocPath })
+	paths := lo.Keys(pages)
+	slices.Sort(paths)
+	for _, p := range paths {
+		if err := addPageBadges(filepath.Join(v.Documents, p), pages[p], change, text); err != nil {
+			return err
+		}
+	}
+	slog.Info("Added badges.", "version", v.Label, "change", change, "pages", len(paths))
+	return nil
+}
+
+func addPageBadges(path string, changes []apiChange, change, text string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	root, err := html.Parse(f)
+	_ = f.Close()
+	if err != nil {
+		return fmt.Errorf("failed to parse HTML: %w", err)
+	}
+	doc := goquery.NewDocumentFromNode(root)
+
+	class := "api-badge-" + change
+	doc.Find("span." + class).Remove()
+	for _, c := range changes {
+		badge := newBadge(class, text)
+		if c.entry.Target == "" {
+			doc.FindMatcher(selTitle).First().AppendNodes(badge)
+			continue
+		}
+
+		// the anchor precedes the signature of the member
+		anchor := doc.Find(`a.dashAnchor[name="` + c.entry.Target + `"]`)
+		if anchor.Length() == 0 {
+			slog.Warn("Member anchor not found.", "path", path, "member", c.entry.String())
+			continue
+		}
+		if next := anchor.First().Next(); next.Length() > 0 {
+			next.AppendNodes(badge)
+		} else {
+			anchor.First().AfterNodes(badge)
+		}
+	}
+
+	return renderHTML(path, root)
+}
+
+func newBadge(class, text string) *html.Node {
+	badge := &html.Node{
+		Type:     html.ElementNode,
+		DataAtom: atom.Span,
+		Data:     atom.Span.String(),
+		Attr: []html.Attribute{
+			{Key: "class", Val: "api-badge " + class},
+			{Key: "style", Val: "margin-left: 0.5em; padding: 0 0.4em; border-radius: 3px; font-size: 75%; font-weight: bold; color: #fff; background: " + badgeColors[class]},
+		},
+	}
+	badge.AppendChild(&html.Node{Type: html.TextNode, Data: text})
+	return badge
+}
+
+var badgeColors = map[string]string{
+	"api-badge-" + changeAdded:   "#2e7d32",
+	"api-badge-" + changeRemoved: "#c62828",
+}
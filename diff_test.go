@@ -0,0 +1,117 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func apiMember(class, typ, name, sig string) apiEntry {
+	return apiEntry{Class: class, Type: typ, Name: name, Signature: sig, DocPath: "classes/class_" + class + ".html"}
+}
+
+func apiClass(name string) apiEntry {
+	return apiEntry{Class: name, Type: "Class", DocPath: "classes/class_" + name + ".html"}
+}
+
+func TestDiffAPI(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new []apiEntry
+		want     []apiChange
+	}{
+		{"unchanged",
+			[]apiEntry{apiClass("Node"), apiMember("Node", "Method", "add_child", "(node: Node)")},
+			[]apiEntry{apiClass("Node"), apiMember("Node", "Method", "add_child", "(node: Node)")},
+			[]apiChange{},
+		},
+		{"added and removed classes",
+			[]apiEntry{apiClass("Node"), apiClass("Old"), apiMember("Old", "Method", "run", "()")},
+			[]apiEntry{apiClass("Node"), apiClass("New"), apiMember("New", "Method", "run", "()")},
+			[]apiChange{
+				{Change: changeAdded, Class: "New", Type: "Class"},
+				{Change: changeRemoved, Class: "Old", Type: "Class"},
+			},
+		},
+		{"changed signature",
+			[]apiEntry{apiMember("Node", "Method", "add_child", "(node: Node)")},
+			[]apiEntry{apiMember("Node", "Method", "add_child", "(node: Node, force: bool)")},
+			[]apiChange{{Change: changeChanged, Class: "Node", Type: "Method", Name: "add_child",
+				Signature: "(node: Node, force: bool)", OldSignature: "(node: Node)"}},
+		},
+		{"renamed",
+			[]apiEntry{apiMember("Node", "Method", "add_kid", "(node: Node)")},
+			[]apiEntry{apiMember("Node", "Method", "add_child", "(node: Node)")},
+			[]apiChange{{Change: changeRenamed, Class: "Node", Type: "Method", Name: "add_child",
+				Signature: "(node: Node)", OldName: "add_kid"}},
+		},
+		// a signature without parameters matches too many members to detect a rename
+		{"not renamed without parameters",
+			[]apiEntry{apiMember("Node", "Method", "start", "() const"), apiMember("Node", "Property", "x", "")},
+			[]apiEntry{apiMember("Node", "Method", "begin", "() const"), apiMember("Node", "Property", "y", "")},
+			[]apiChange{
+				{Change: changeAdded, Class: "Node", Type: "Method", Name: "begin", Signature: "() const"},
+				{Change: changeRemoved, Class: "Node", Type: "Method", Name: "start", Signature: "() const"},
+				{Change: changeRemoved, Class: "Node", Type: "Property", Name: "x"},
+				{Change: changeAdded, Class: "Node", Type: "Property", Name: "y"},
+			},
+		},
+		{"another type",
+			[]apiEntry{apiMember("Node", "Method", "ready", "()")},
+			[]apiEntry{apiMember("Node", "Signal", "ready", "()")},
+			[]apiChange{
+				{Change: changeRemoved, Class: "Node", Type: "Method", Name: "ready", Signature: "()"},
+				{Change: changeAdded, Class: "Node", Type: "Signal", Name: "ready", Signature: "()"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diffAPI(tt.old, tt.new)
+			for i := range got {
+				got[i].entry = apiEntry{}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffAPI() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPairEntries(t *testing.T) {
+	old := []apiEntry{apiMember("A", "Method", "a", "()"), apiMember("A", "Method", "b", "()"), apiMember("A", "Method", "b", "()"), apiMember("A", "Method", "", "")}
+	new := []apiEntry{apiMember("A", "Method", "b", "()"), apiMember("A", "Method", "c", "()"), apiMember("A", "Method", "", "")}
+	byName := func(e apiEntry) (string, bool) { return e.Name, e.Name != "" }
+
+	pairs, oldRest, newRest := pairEntries(old, new, byName)
+	// an entry is paired once, and entries without a key are never paired
+	if want := []apiPair{{old[1], new[0]}}; !reflect.DeepEqual(pairs, want) {
+		t.Errorf("pairs = %+v, want %+v", pairs, want)
+	}
+	if want := []apiEntry{old[0], old[2], old[3]}; !reflect.DeepEqual(oldRest, want) {
+		t.Errorf("oldRest = %+v, want %+v", oldRest, want)
+	}
+	if want := []apiEntry{new[1], new[2]}; !reflect.DeepEqual(newRest, want) {
+		t.Errorf("newRest = %+v, want %+v", newRest, want)
+	}
+}
+
+func TestAPIEntries(t *testing.T) {
+	const page = "classes/class_node.html"
+	const target = "//dash_ref/Method/add_child/0"
+	rows := []SearchIndex{
+		{Name: "Node", Type: "Class", Path: page},
+		{Name: "add_child(node: Node)", Type: "Method", Path: makeSearchIndexPath(page, "add_child(node: Node)", "add_child(node: Node)", "Node", target)},
+		// rows derived from add_child
+		{Name: "Node.add_child(node: Node)", Type: "Method", Path: makeSearchIndexPath(page, "Node.add_child(node: Node)", "add_child(node: Node)", "Node", target)},
+		{Name: "AddChild", Type: "Method", Path: makeSearchIndexPath(page, "AddChild", "add_child(node: Node)", "Node", target)},
+		{Name: "Methods", Type: "Section", Path: makeSearchIndexPath(page, "Methods", "Methods", "Node", "//dash_ref/Section/Methods/0")},
+		{Name: "Scenes", Type: "Guide", Path: "tutorials/scenes.html"},
+	}
+	want := []apiEntry{
+		{Class: "Node", Type: "Class", DocPath: page},
+		{Class: "Node", Type: "Method", Name: "add_child", Signature: "(node: Node)", DocPath: page, Target: target},
+	}
+	if got := apiEntries(rows); !reflect.DeepEqual(got, want) {
+		t.Errorf("apiEntries() =\n%+v\nwant\n%+v", got, want)
+	}
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// The types in this file model the classes of extension_api.json, as written by
+// godot --dump-extension-api. Only the parts used to document a class are included.
+
+type extensionAPI struct {
+	Classes []extensionClass `json:"classes"`
+}
+
+type extensionClass struct {
+	Name       string              `json:"name"`
+	Inherits   string              `json:"inherits"`
+	APIType    string              `json:"api_type"`
+	Constants  []extensionConstant `json:"constants"`
+	Enums      []extensionEnum     `json:"enums"`
+	Methods    []extensionMethod   `json:"methods"`
+	Signals    []extensionMethod   `json:"signals"`
+	Properties []extensionProperty `json:"properties"`
+}
+
+type extensionConstant struct {
+	Name  string `json:"name"`
+	Value int64  `json:"value"`
+}
+
+type extensionEnum struct {
+	Name       string              `json:"name"`
+	IsBitfield bool                `json:"is_bitfield"`
+	Values     []extensionConstant `json:"values"`
+}
+
+type extensionMethod struct {
+	Name        string              `json:"name"`
+	IsConst     bool                `json:"is_const"`
+	IsStatic    bool                `json:"is_static"`
+	IsVirtual   bool                `json:"is_virtual"`
+	IsVararg    bool                `json:"is_vararg"`
+	ReturnValue *extensionArgument  `json:"return_value"`
+	Arguments   []extensionArgument `json:"arguments"`
+}
+
+type extensionArgument struct {
+	Name         string `json:"name"`
+	Type         string `json:"type"`
+	DefaultValue string `json:"default_value"`
+}
+
+type extensionProperty struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Setter string `json:"setter"`
+	Getter string `json:"getter"`
+}
+
+// Entry types of classes that are not part of the engine.
+const (
+	extensionEntryType = "Extension" // classes of GDExtension libraries
+	pluginEntryType    = "Plugin"    // classes documented by the doc XML of addons
+)
+
+// extensionType converts a type of extension_api.json to the type and enum used by the
+// class reference XML, e.g. enum::Node.ProcessMode is an int of the enum Node.ProcessMode.
+func extensionType(typ string) (string, string) {
+	for _, prefix := range []string{"enum::", "bitfield::"} {
+		if enum, ok := strings.CutPrefix(typ, prefix); ok {
+			return "int", enum
+		}
+	}
+	if elem, ok := strings.CutPrefix(typ, "typedarray::"); ok {
+		return elem + "[]", ""
+	}
+	return typ, ""
+}
+
+func (m *extensionMethod) toXML() xmlMethod {
+	var qualifiers []string
+	for _, q := range []struct {
+		set  bool
+		name string
+	}{
+		{m.IsVirtual, "virtual"},
+		{m.IsConst, "const"},
+		{m.IsStatic, "static"},
+		{m.IsVararg, "vararg"},
+	} {
+		if q.set {
+			qualifiers = append(qualifiers, q.name)
+		}
+	}
+
+	xm := xmlMethod{
+		Name:       m.Name,
+		Qualifiers: strings.Join(qualifiers, " "),
+	}
+	if m.ReturnValue != nil {
+		typ, enum := extensionType(m.ReturnValue.Type)
+		xm.Return = &xmlReturn{Type: typ, Enum: enum}
+	}
+	for i, a := range m.Arguments {
+		typ, enum := extensionType(a.Type)
+		xm.Params = append(xm.Params, xmlParam{
+			Index:   i,
+			Name:    a.Name,
+			Type:    typ,
+			Enum:    enum,
+			Default: a.DefaultValue,
+		})
+	}
+	return xm
+}
+
+// toXML converts the class to the model of the class reference XML. extension_api.json
+// has no descriptions, so only the members are documented.
+func (c *extensionClass) toXML(path string) xmlClass {
+	xc := xmlClass{
+		Name:             c.Name,
+		Inherits:         c.Inherits,
+		BriefDescription: "Extension class provided by a GDExtension library.",
+		FilePath:         path,
+		EntryType:        extensionEntryType,
+	}
+	for i := range c.Methods {
+		xc.Methods = append(xc.Methods, c.Methods[i].toXML())
+	}
+	for i := range c.Signals {
+		xc.Signals = append(xc.Signals, c.Signals[i].toXML())
+	}
+	for _, p := range c.Properties {
+		typ, enum := extensionType(p.Type)
+		xc.Members = append(xc.Members, xmlMember{
+			Name:   p.Name,
+			Type:   typ,
+			Enum:   enum,
+			Setter: p.Setter,
+			Getter: p.Getter,
+		})
+	}
+	for _, k := range c.Constants {
+		xc.Constants = append(xc.Constants, xmlConstant{
+			Name:  k.Name,
+			Value: strconv.FormatInt(k.Value, 10),
+		})
+	}
+	for _, e := range c.Enums {
+		for _, v := range e.Values {
+			xc.Constants = append(xc.Constants, xmlConstant{
+				Name:       v.Name,
+				Value:      strconv.FormatInt(v.Value, 10),
+				Enum:       e.Name,
+				IsBitfield: e.IsBitfield,
+			})
+		}
+	}
+	return xc
+}
+
+// readExtensionAPI reads the extension classes of an extension_api.json file. The dump
+// also includes every engine class, which are skipped as they are already documented.
+func readExtensionAPI(path string) ([]xmlClass, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	var api extensionAPI
+	if err = json.Unmarshal(b, &api); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	var classes []xmlClass
+	for i := range api.Classes {
+		c := &api.Classes[i]
+		if c.APIType == "core" || c.APIType == "editor" {
+			continue
+		}
+		classes = append(classes, c.toXML(path))
+	}
+	if len(classes) == 0 {
+		slog.Warn("No extension classes found.", "path", path)
+	}
+	return classes, nil
+}
+
+// processExtensions adds the classes of GDExtension libraries and addons to the docset,
+// under their own entry types.
+func processExtensions(ctx context.Context) (*xmlClassSet, error) {
+	slog.Info("Process extensions", "api", extensionAPIPaths, "xml", extensionXMLPaths)
+
+	var api []xmlClass
+	for _, path := range extensionAPIPaths {
+		c, err := readExtensionAPI(path)
+		if err != nil {
+			return nil, err
+		}
+		api = append(api, c...)
+	}
+
+	var docs []xmlClass
+	if len(extensionXMLPaths) > 0 {
+		var err error
+		docs, err = readXMLClasses(ctx, extensionXMLPaths)
+		if err != nil {
+			return nil, err
+		}
+		for i := range docs {
+			docs[i].EntryType = pluginEntryType
+		}
+	}
+
+	set := newXMLClassSet(selectXMLClasses(mergeExtensionClasses(api, docs)))
+	for _, c := range set.classes {
+		if _, ok := knownClasses[c.Name]; ok {
+			slog.Warn("Extension class replaces a class of the engine.", "class", c.Name)
+		}
+	}
+
+	return set, writeXMLClasses(ctx, set)
+}
+
+// mergeExtensionClasses returns the classes of extension_api.json and of the doc XML. A
+// GDExtension usually ships both for the same classes, which are merged into one class
+// with the descriptions of the doc XML and the signatures of extension_api.json. A class
+// in more than one extension_api.json file or doc XML directory is taken from the last.
+func mergeExtensionClasses(api, docs []xmlClass) []xmlClass {
+	api = uniqueClasses(api)
+	byName := make(map[string]int, len(api))
+	for i, c := range api {
+		byName[c.Name] = i
+	}
+	classes := slices.Clone(api)
+	for _, doc := range uniqueClasses(docs) {
+		i, ok := byName[doc.Name]
+		if !ok {
+			classes = append(classes, doc)
+			continue
+		}
+		classes[i] = mergeExtensionClass(doc, api[i])
+	}
+	return classes
+}
+
+// mergeExtensionClass returns the class documented by doc, with the signatures of the
+// members of api. Members missing from doc are added without a description.
+func mergeExtensionClass(doc, api xmlClass) xmlClass {
+	c := doc
+	c.Inherits = api.Inherits
+	c.EntryType = api.EntryType
+	c.Methods = mergeByName(doc.Methods, api.Methods, func(m xmlMethod) string { return m.Name }, mergeMethod)
+	c.Signals = mergeByName(doc.Signals, api.Signals, func(m xmlMethod) string { return m.Name }, mergeMethod)
+	c.Members = mergeByName(doc.Members, api.Members, func(m xmlMember) string { return m.Name }, func(d *xmlMember, a xmlMember) {
+		d.Type, d.Enum, d.Setter, d.Getter = a.Type, a.Enum, a.Setter, a.Getter
+	})
+	c.Constants = mergeByName(doc.Constants, api.Constants, func(k xmlConstant) string { return k.Name }, func(d *xmlConstant, a xmlConstant) {
+		d.Value, d.Enum, d.IsBitfield = a.Value, a.Enum, a.IsBitfield
+	})
+	return c
+}
+
+func mergeMethod(d *xmlMethod, a xmlMethod) {
+	d.Qualifiers, d.Return, d.Params = a.Qualifiers, a.Return, a.Params
+}
+
+// mergeByName merges the items of api into the items of doc with the same name, and
+// appends the items of api that doc does not have.
+func mergeByName[T any](doc, api []T, name func(T) string, merge func(*T, T)) []T {
+	items := slices.Clone(doc)
+	index := make(map[string]int, len(items))
+	for i, item := range items {
+		index[name(item)] = i
+	}
+	for _, a := range api {
+		if i, ok := index[name(a)]; ok {
+			merge(&items[i], a)
+		} else {
+			items = append(items, a)
+		}
+	}
+	return items
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMergeExtensionClasses(t *testing.T) {
+	api := []xmlClass{
+		{Name: "Terrain", Inherits: "Node3D", FilePath: "a/extension_api.json", EntryType: extensionEntryType,
+			Methods: []xmlMethod{{Name: "build", Qualifiers: "const"}}},
+		{Name: "Brush", Inherits: "Resource", FilePath: "a/extension_api.json", EntryType: extensionEntryType},
+		// the same library dumped again, with a newer version of Terrain
+		{Name: "Terrain", Inherits: "Node3D", FilePath: "b/extension_api.json", EntryType: extensionEntryType,
+			Methods: []xmlMethod{{Name: "build", Qualifiers: "const"}, {Name: "clear"}}},
+	}
+	docs := []xmlClass{
+		{Name: "Terrain", Description: "Old terrain.", FilePath: "a/doc_classes/Terrain.xml", EntryType: pluginEntryType},
+		{Name: "Terrain", Description: "A terrain.", FilePath: "b/doc_classes/Terrain.xml", EntryType: pluginEntryType,
+			Methods: []xmlMethod{{Name: "build", Description: "Builds the terrain."}}},
+		{Name: "Spawner", Inherits: "Node", FilePath: "b/doc_classes/Spawner.xml", EntryType: pluginEntryType},
+	}
+
+	classes := mergeExtensionClasses(api, docs)
+	var names []string
+	for _, c := range classes {
+		names = append(names, c.Name)
+	}
+	if want := []string{"Terrain", "Brush", "Spawner"}; !slices.Equal(names, want) {
+		t.Fatalf("classes = %v, want %v", names, want)
+	}
+
+	terrain := classes[0]
+	if terrain.Description != "A terrain." || terrain.FilePath != "b/doc_classes/Terrain.xml" {
+		t.Errorf("Terrain documented by %s: %q, want the last doc XML", terrain.FilePath, terrain.Description)
+	}
+	if terrain.Inherits != "Node3D" || terrain.EntryType != extensionEntryType {
+		t.Errorf("Terrain inherits %q with entry type %q, want those of extension_api.json", terrain.Inherits, terrain.EntryType)
+	}
+	want := []xmlMethod{
+		{Name: "build", Qualifiers: "const", Description: "Builds the terrain."},
+		{Name: "clear"},
+	}
+	if len(terrain.Methods) != len(want) {
+		t.Fatalf("Terrain methods = %+v, want %+v", terrain.Methods, want)
+	}
+	for i, m := range want {
+		got := terrain.Methods[i]
+		if got.Name != m.Name || got.Qualifiers != m.Qualifiers || got.Description != m.Description {
+			t.Errorf("Terrain method %d = %+v, want %+v", i, got, m)
+		}
+	}
+
+	if set := newXMLClassSet(classes); set.byName["Terrain"] != &set.classes[0] {
+		t.Errorf("byName[Terrain] is not the merged class")
+	}
+}
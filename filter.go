@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// validatePatterns returns an error for the first invalid glob of patterns, given to flag.
+func validatePatterns(flag string, patterns []string) error {
+	for _, p := range patterns {
+		if !doublestar.ValidatePattern(p) {
+			return fmt.Errorf("invalid %s pattern %q", flag, p)
+		}
+	}
+	return nil
+}
+
+// includePath reports whether the page at docPath, such as classes/class_node.html or
+// tutorials/2d/index.html, is processed: it must match one of the --include globs, if any,
+// and none of the --exclude globs.
+func includePath(docPath string) bool {
+	match := func(p string) bool {
+		ok, _ := doublestar.Match(p, docPath) // the patterns are validated by process
+		return ok
+	}
+	if len(includes) > 0 && !slices.ContainsFunc(includes, match) {
+		return false
+	}
+	return !slices.ContainsFunc(excludes, match)
+}
@@ -2,13 +2,18 @@ package main
 
 import (
 	"bytes"
+	"cmp"
+	"context"
 	"fmt"
 	"log/slog"
 	"net/url"
 	"os"
+	"os/signal"
 	"path/filepath"
-	"regexp"
+	"slices"
 	"strings"
+	"syscall"
+	"text/template"
 	"unsafe"
 
 	"github.com/PuerkitoBio/goquery"
@@ -33,63 +38,174 @@ var (
 	// arguments
 	noDB       bool
 	noClasses  bool
-	docsPath   string
+	docsPaths  []string
 	docsetPath string
-	pathFilter regexFlag
+	includes   []string
+	excludes   []string
+	jobs       int
+	dbBatch    int
+	serial     bool
+	csharp     bool
+	langCode   string
+	feedURL    string
+	xmlPaths   []string
+	deprecated string
+	inherited  bool
+	qualified  bool
+	hoverPath  string
+	noInherit  []string
+	format     string
+	offline    bool
+	samples    string
+	codeLinks  int
+	backlinks  bool
+	strategy   = strategyFlag{t: parallel.StrategyUseDefaults}
+	// extension sources
+	extensionAPIPaths []string
+	extensionXMLPaths []string
 )
 
-type regexFlag struct{ re *regexp.Regexp }
+// strategyNames maps the values accepted by the --strategy flag to the
+// parallel strategies they select.
+var strategyNames = map[string]parallel.StrategyType{
+	"preassign":     parallel.StrategyPreassignIndices,
+	"fetch-next":    parallel.StrategyFetchNextIndex,
+	"work-stealing": parallel.StrategyWorkStealing,
+	"largest-first": parallel.StrategyLargestFirst,
+}
 
-func (r *regexFlag) String() string {
-	if r.re == nil {
-		return ""
-	}
-	return r.re.String()
+type strategyFlag struct {
+	name string
+	t    parallel.StrategyType
 }
 
-func (r *regexFlag) Set(s string) error {
-	re, err := regexp.Compile(s)
-	if err != nil {
-		return err
+func (s *strategyFlag) String() string {
+	return s.name
+}
+
+func (s *strategyFlag) Set(v string) error {
+	t, ok := strategyNames[v]
+	if !ok {
+		names := lo.Keys(strategyNames)
+		slices.Sort(names)
+		return fmt.Errorf("unknown strategy %q, expected one of: %s", v, strings.Join(names, ", "))
 	}
-	r.re = re
+	s.name = v
+	s.t = t
 	return nil
 }
 
-func (r *regexFlag) Type() string {
-	return "REGEX"
+func (s *strategyFlag) Type() string {
+	return "STRATEGY"
 }
 
 func init() {
-	cmd.Flags().StringVar(&docsPath, "docs-path", "", "The path to the godot-docs source")
-	cmd.Flags().StringVar(&docsetPath, "docset-path", "", "The base path to the Godot.docset")
+	cmd.Flags().StringArrayVar(&docsPaths, "docs-path", nil, "The path to the godot-docs source, optionally tagged with a version as VERSION=PATH (repeatable)")
+	cmd.Flags().StringSliceVar(&xmlPaths, "xml-path", nil, "A directory of class reference XML files, such as doc/classes, used instead of the class pages in --docs-path (repeatable)")
+	cmd.Flags().StringVar(&docsetPath, "docset-path", "", "The base path to the Godot.docset, or the output directory when building multiple versions")
+	cmd.Flags().StringVar(&feedURL, "feed-url", "", "The base URL of the docset archives, used to write a feed when building multiple versions")
+	cmd.Flags().StringSliceVar(&extensionAPIPaths, "extension-api", nil, "An extension_api.json file with GDExtension classes to add to the docset (repeatable)")
+	cmd.Flags().StringSliceVar(&extensionXMLPaths, "extension-xml", nil, "A directory of class reference XML files of an addon to add to the docset (repeatable)")
+	cmd.Flags().StringVar(&langCode, "lang", "en", "The language of the documentation, e.g. fr or zh_CN")
+	cmd.Flags().StringVar(&deprecated, "deprecated", deprecatedShow, "How deprecated APIs are indexed: show or hide")
+	cmd.Flags().BoolVar(&qualified, "qualified-names", false, "Also index class members by their qualified names, e.g. Node.add_child or Node.ProcessMode.PROCESS_MODE_INHERIT")
+	cmd.Flags().BoolVar(&inherited, "inherited-members", false, "Also index the members each class inherits, e.g. Sprite2D.position")
+	cmd.Flags().StringSliceVar(&noInherit, "inherit-skip", []string{"Object"}, "Classes whose members are not indexed as inherited members (repeatable)")
+	cmd.Flags().StringVar(&hoverPath, "hover-json", "", "Also write the descriptions, signatures and URLs of every class member to this JSON file, for editor hovers")
+	cmd.Flags().StringVar(&format, "format", formatDocset, "The output format: docset, markdown to write each class page and guide as a Markdown file to --docset-path, or man to write a man page per class")
+	cmd.Flags().BoolVar(&offline, "offline", false, "Remove the analytics and search scripts of the pages, link to the local copies of pages of the online documentation and mark external links")
+	cmd.Flags().StringVar(&samples, "code-samples", samplesTabs, "How the GDScript and C# code samples are shown: tabs, static (one after the other), gdscript or csharp (only the samples of one language; csharp also drops @GDScript)")
+	cmd.Flags().IntVar(&codeLinks, "code-links", 0, "Link the class and member names in the code of guides to the class reference, adding at most this many links per page (0 disables)")
+	cmd.Flags().BoolVar(&backlinks, "referenced-by", false, "Add a \"Referenced by\" section to each class page, listing the guides linking to the class")
+	cmd.Flags().BoolVar(&csharp, "csharp-aliases", false, "Also index class members by their C# names, e.g. AddChild for add_child")
 	cmd.Flags().BoolVar(&noDB, "no-db", false, "Do not create the database (TESTING)")
 	cmd.Flags().BoolVar(&noClasses, "no-classes", false, "Do not process classes (TESTING)")
-	cmd.Flags().Var(&pathFilter, "path-filter", "A regex pattern to filter the paths to process (TESTING)")
-	_ = cobra.MarkFlagRequired(cmd.Flags(), "docs-path")
+	cmd.Flags().StringArrayVar(&includes, "include", nil, "Only process the class pages and guides matching this glob, e.g. 'tutorials/2d/**' or 'classes/class_node*.html' (repeatable)")
+	cmd.Flags().StringArrayVar(&excludes, "exclude", nil, "Do not process the class pages and guides matching this glob, e.g. 'contributing/**' (repeatable)")
+	cmd.Flags().IntVar(&jobs, "jobs", workers, "The number of files to process concurrently")
+	cmd.Flags().IntVar(&dbBatch, "db-batch-size", batchSize, "The number of rows to insert per database batch")
+	cmd.Flags().BoolVar(&serial, "serial", false, "Process files one at a time, in order, on a single goroutine")
+	cmd.Flags().Var(&strategy, "strategy", "How work is distributed among jobs: preassign, fetch-next, work-stealing or largest-first")
 	_ = cobra.MarkFlagRequired(cmd.Flags(), "docset-path")
 }
 
 func main() {
-	_ = cmd.Execute()
+	// cancel processing on interrupt, so partially written output is reported as an error
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	_ = cmd.ExecuteContext(ctx)
 }
 
+// default values for the --jobs and --db-batch-size flags
 const (
 	workers   = 8
 	batchSize = 1500
 )
 
+// values of the --format flag
+const (
+	formatDocset   = "docset"
+	formatMarkdown = "markdown"
+	formatMan      = "man"
+)
+
 type SearchIndex struct {
 	ID   int64  `gorm:"primaryKey;column:id"`
 	Name string `gorm:"column:name;uniqueIndex:anchor"`
 	Type string `gorm:"column:type;uniqueIndex:anchor"`
 	Path string `gorm:"column:path;uniqueIndex:anchor"`
+
+	Member *memberRef `gorm:"-"` // set for the rows of class members
+	Status apiStatus  `gorm:"-"` // set for the rows of deprecated and experimental APIs
 }
 
 func (si SearchIndex) TableName() string {
 	return "searchIndex"
 }
 
+// memberRef identifies the anchor of a class member in the docset. It is not stored in
+// the search index, but used to derive additional rows for a member.
+type memberRef struct {
+	DocPath string // DocPath is the path of the class page
+	Class   string // Class is the name of the class declaring the member
+	Target  string // Target is the dashtoc anchor of the member
+}
+
+// newMemberRow returns the search index row of a class member.
+func newMemberRow(docPath, name, etype, className, target string, status apiStatus) SearchIndex {
+	return SearchIndex{
+		Name:   name,
+		Type:   etype,
+		Path:   makeSearchIndexPath(docPath, name, name, menuDescription(className, status), target),
+		Member: &memberRef{DocPath: docPath, Class: className, Target: target},
+		Status: status,
+	}
+}
+
+// newClassRow returns the search index row of a class page. The menu description of
+// deprecated and experimental classes shows their status.
+func newClassRow(docPath, name, etype string, status apiStatus) SearchIndex {
+	row := SearchIndex{Name: name, Type: etype, Path: docPath, Status: status}
+	if status != statusStable {
+		row.Path = makeSearchIndexPath(docPath, name, name, menuDescription("", status), "")
+	}
+	return row
+}
+
+// alias returns a row with a different name and menu description for the same member. The
+// original name of the alias is the name of the member, which tells the rows derived from
+// members apart from the members.
+func (si SearchIndex) alias(name, desc string) SearchIndex {
+	return SearchIndex{
+		Name:   name,
+		Type:   si.Type,
+		Path:   makeSearchIndexPath(si.Member.DocPath, name, si.Name, menuDescription(desc, si.Status), si.Member.Target),
+		Member: si.Member,
+		Status: si.Status,
+	}
+}
+
 var (
 	wyNavSide        = css.MustCompile("nav.wy-nav-side")
 	rstVersions      = css.MustCompile("div.rst-versions")
@@ -125,24 +241,129 @@ func cleanupDocument(top *html.Node, doc *goquery.Document) {
 
 var (
 	db         *gorm.DB
-	targetPath string // targetPath is the Documents directory in the target docset
+	executor   parallel.ExecutorRuntime // executor is shared by every processing stage
+	targetPath string                   // targetPath is the Documents directory in the target docset
+	// knownClasses is the set of class names with a page in the docset
+	knownClasses = make(map[string]struct{})
+	// the docset being built, set by buildDocset
+	docsPath string // docsPath is the documentation of the docset, if any
+	lang     locale // lang is the language of the documentation
+	version  string // version is the Godot version of the docset, if known
 	// common selectors
 	selHead  = css.MustCompile("head")
 	selTitle = css.MustCompile("h1")
 )
 
 func process(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if len(docsPaths) == 0 && len(xmlPaths) == 0 && len(extensionAPIPaths) == 0 && len(extensionXMLPaths) == 0 {
+		return errors.New("one of --docs-path, --xml-path, --extension-api or --extension-xml must be specified")
+	}
+	if jobs < 1 {
+		return fmt.Errorf("--jobs must be at least 1, got %d", jobs)
+	}
+	if codeLinks < 0 {
+		return fmt.Errorf("--code-links must not be negative, got %d", codeLinks)
+	}
+	if dbBatch < 1 {
+		return fmt.Errorf("--db-batch-size must be at least 1, got %d", dbBatch)
+	}
+	if deprecated != deprecatedShow && deprecated != deprecatedHide {
+		return fmt.Errorf("unknown --deprecated value %q, expected show or hide", deprecated)
+	}
+	if samples != samplesTabs && samples != samplesStatic && samples != samplesGDScript && samples != samplesCSharp {
+		return fmt.Errorf("unknown --code-samples value %q, expected tabs, static, gdscript or csharp", samples)
+	}
+	if err := validatePatterns("--include", includes); err != nil {
+		return err
+	}
+	if err := validatePatterns("--exclude", excludes); err != nil {
+		return err
+	}
+	if format != formatDocset && format != formatMarkdown && format != formatMan {
+		return fmt.Errorf("unknown --format value %q, expected docset, markdown or man", format)
+	}
+	if format != formatDocset && (len(xmlPaths) > 0 || len(extensionAPIPaths) > 0 || len(extensionXMLPaths) > 0 || hoverPath != "") {
+		return fmt.Errorf("--format=%s only supports the pages of --docs-path", format)
+	}
+	inputs, err := parseDocsInputs(docsPaths)
+	if err != nil {
+		return err
+	}
+	l, err := lookupLocale(langCode)
+	if err != nil {
+		return err
+	}
+	if serial {
+		executor = parallel.SerialExecutor()
+	} else {
+		executor = parallel.NewExecutor().WithNumGoroutines(jobs).WithStrategy(strategy.t)
+	}
+
+	if format != formatDocset && len(inputs) > 1 {
+		return fmt.Errorf("--format=%s builds a single version", format)
+	}
+
+	if len(inputs) <= 1 {
+		cfg := buildConfig{DocsetPath: docsetPath, Lang: l}
+		if len(inputs) == 1 {
+			cfg.DocsPath = inputs[0].Path
+			cfg.Version = inputs[0].Version
+		}
+		return buildDocset(ctx, cfg)
+	}
+
+	return buildVersions(ctx, inputs, docsetPath, l)
+}
+
+// buildConfig describes a docset to build.
+type buildConfig struct {
+	DocsPath   string // DocsPath is the godot-docs tree of the docset, if any
+	DocsetPath string
+	Version    string // Version is the Godot version of the docset, if known
+	Lang       locale
+}
+
+// buildDocset builds the docset at cfg.DocsetPath from cfg.DocsPath and the class sources.
+// With --format=markdown or man, only the pages are written, as Markdown files or man pages
+// in cfg.DocsetPath.
+func buildDocset(ctx context.Context, cfg buildConfig) error {
+	pagesOnly := format != formatDocset
+	docsPath, version, lang = cfg.DocsPath, cfg.Version, cfg.Lang
+
+	// every docset has its own classes
+	knownClasses = make(map[string]struct{})
+	hierarchy = newClassHierarchy()
+	manPages = make(map[string]string)
+	offlineRewrites = newOfflineReport()
+	references = newGuideReferences()
+
 	// Open the database
-	dbFilename := filepath.Join(docsetPath, "Contents/Resources/docSet.dsidx")
+	dbFilename := filepath.Join(cfg.DocsetPath, "Contents/Resources/docSet.dsidx")
 	dsn := fmt.Sprintf("%s?_busy_timeout=", dbFilename)
 	var err error
-	if noDB == false {
+	db = nil
+	if noDB == false && !pagesOnly {
+		if err = os.MkdirAll(filepath.Dir(dbFilename), 0755); err != nil {
+			return fmt.Errorf("failed to create database directory: %w", err)
+		}
+
+		// start from an empty file, so the database only depends on the rows written
+		if err = os.Remove(dbFilename); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove database: %w", err)
+		}
+
 		db, err = gorm.Open(sqlite.Open(dsn), &gorm.Config{
-			CreateBatchSize: batchSize,
+			CreateBatchSize: dbBatch,
 		})
 		if err != nil {
 			return fmt.Errorf("failed to open database: %w", err)
 		}
+		defer func() {
+			if sqlDB, err := db.DB(); err == nil {
+				_ = sqlDB.Close()
+			}
+		}()
 
 		db.Exec("PRAGMA synchronous = OFF; PRAGMA JOURNAL_MODE = memory")
 
@@ -151,27 +372,98 @@ func process(cmd *cobra.Command, args []string) error {
 		_ = migrator.AutoMigrate(&SearchIndex{})
 	}
 
-	// start with index.html
+	targetPath = filepath.Join(cfg.DocsetPath, "Contents/Resources/Documents")
+	if pagesOnly {
+		targetPath = cfg.DocsetPath
+	}
+
+	var classSet, extensionSet *xmlClassSet
+	if noClasses == false {
+		if len(xmlPaths) > 0 {
+			classSet, err = processXMLClasses(ctx)
+		} else if docsPath != "" {
+			err = processClassesIndex(ctx)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(extensionAPIPaths) > 0 || len(extensionXMLPaths) > 0 {
+		extensionSet, err = processExtensions(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(hierarchy.parents) > 0 && !pagesOnly {
+		if err = writeHierarchyPage(); err != nil {
+			return err
+		}
+		if hoverPath != "" {
+			if err = writeHoverDatabase(ctx, hoverPath); err != nil {
+				return err
+			}
+		}
+	}
+
+	if format == formatMan {
+		// guides have no man pages
+		return writeWhatis()
+	}
+
+	if docsPath != "" {
+		err = processDocs(ctx)
+		if err == nil && backlinks && !pagesOnly {
+			err = writeReferences(ctx)
+		}
+	} else {
+		// without the Sphinx documentation there is no index page, so create one
+		// listing the classes
+		err = writeXMLIndex(classSet, extensionSet)
+	}
+	if err != nil {
+		return err
+	}
+
+	if inherited {
+		writeRows(hierarchy.inheritedRows(noInherit))
+	}
+	if offline {
+		offlineRewrites.log()
+	}
+	if pagesOnly {
+		return nil
+	}
+
+	var plist bytes.Buffer
+	err = infoPlist.Execute(&plist, bundle{Lang: lang, Version: version})
+	if err == nil {
+		err = os.WriteFile(filepath.Join(cfg.DocsetPath, "Contents/Info.plist"), plist.Bytes(), 0644)
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to write Info.plist")
+	}
+
+	// copy icon.png to the docset
+
+	return nil
+}
+
+// processDocs processes the guides of the Sphinx documentation, starting with index.html.
+func processDocs(ctx context.Context) error {
 	path := filepath.Join(docsPath, "index.html")
 	f, err := os.Open(path)
 	if err != nil {
 		return fmt.Errorf("failed to open file: %w", err)
 	}
+	defer func() { _ = f.Close() }()
 	root, err := html.Parse(f)
 	if err != nil {
 		return fmt.Errorf("failed to parse HTML: %w", err)
 	}
 
-	targetPath = filepath.Join(docsetPath, "Contents/Resources/Documents")
-
-	if noClasses == false {
-		err = processClassesIndex()
-		if err != nil {
-			return err
-		}
-	}
-
-	err = processGuides(root)
+	err = processGuides(ctx, root)
 	if err != nil {
 		return err
 	}
@@ -179,37 +471,76 @@ func process(cmd *cobra.Command, args []string) error {
 	doc := goquery.NewDocumentFromNode(root)
 
 	err = writeHTML(filepath.Join(targetPath, "index.html"), root, doc)
-	if err != nil {
+	if err != nil || format == formatMarkdown {
 		return err
 	}
 
-	err = os.WriteFile(filepath.Join(docsetPath, "Contents/Resources/Documents/_static/css/dev.css"), []byte(devCss), 0644)
+	err = writeFile(filepath.Join(targetPath, "_static/css/dev.css"), []byte(devCss))
 	if err != nil {
 		return errors.Wrap(err, "failed to write dev.css")
 	}
 
-	err = os.WriteFile(filepath.Join(docsetPath, "Contents/Info.plist"), []byte(infoPlist), 0644)
-	if err != nil {
-		return errors.Wrap(err, "failed to write Info.plist")
-	}
+	return nil
+}
 
-	// copy icon.png to the docset
+// expandMemberRows returns rows with the additional rows for class members enabled by the
+// command line options.
+func expandMemberRows(rows []SearchIndex) []SearchIndex {
+	var expanded []SearchIndex
+	if csharp {
+		expanded = append(expanded, csharpAliases(rows)...)
+	}
+	if qualified {
+		expanded = append(expanded, qualifiedRows(rows)...)
+	}
+	return append(rows, expanded...)
+}
 
-	return nil
+// qualifiedRows returns a row for each class member in rows, named as the member is
+// referenced in code, e.g. Node.add_child, or Node.ProcessMode.PROCESS_MODE_INHERIT for
+// the value of an enum.
+func qualifiedRows(rows []SearchIndex) []SearchIndex {
+	var qualified []SearchIndex
+	for _, row := range rows {
+		if row.Member == nil {
+			continue
+		}
+		class := row.Member.Class
+		qualified = append(qualified, row.alias(class+"."+strings.TrimSpace(row.Name), class))
+	}
+	return qualified
 }
 
+// writeRows inserts rows into the search index, applying the --deprecated option.
 func writeRows(rows []SearchIndex) {
-	if db == nil {
+	if deprecated == deprecatedHide {
+		rows = lo.Filter(rows, func(r SearchIndex, _ int) bool { return r.Status != statusDeprecated })
+	}
+	insertRows(rows)
+}
+
+// insertRows inserts rows into the search index. The rows are sorted first, so the row IDs
+// do not depend on the order in which files were processed.
+func insertRows(rows []SearchIndex) {
+	if db == nil || len(rows) == 0 {
 		return
 	}
 
+	slices.SortFunc(rows, func(a, b SearchIndex) int {
+		return cmp.Or(
+			cmp.Compare(a.Type, b.Type),
+			cmp.Compare(a.Name, b.Name),
+			cmp.Compare(a.Path, b.Path),
+		)
+	})
+
 	db.Clauses(clause.OnConflict{
 		Columns:   []clause.Column{{Name: "name"}, {Name: "type"}, {Name: "path"}},
 		DoNothing: true,
 	}).Create(rows)
 }
 
-func processClassesIndex() (err error) {
+func processClassesIndex(ctx context.Context) (err error) {
 	slog.Info("Process classes")
 
 	// open class index file
@@ -228,36 +559,36 @@ func processClassesIndex() (err error) {
 	doc := goquery.NewDocumentFromNode(root)
 
 	// globals
-	nodes := doc.Find("section#globals li.toctree-l1 > a")
-	err = processClasses(nodes, "Global")
+	nodes := classSectionLinks(doc, "globals")
+	err = processClasses(ctx, nodes, "Global")
 	if err != nil {
 		return err
 	}
 
 	// nodes
-	nodes = doc.Find("section#nodes li.toctree-l1 > a")
-	err = processClasses(nodes, "Class")
+	nodes = classSectionLinks(doc, "nodes")
+	err = processClasses(ctx, nodes, "Class")
 	if err != nil {
 		return err
 	}
 
 	// resources
-	nodes = doc.Find("section#resources li.toctree-l1 > a")
-	err = processClasses(nodes, "Resource")
+	nodes = classSectionLinks(doc, "resources")
+	err = processClasses(ctx, nodes, "Resource")
 	if err != nil {
 		return err
 	}
 
 	// other-objects
-	nodes = doc.Find("section#other-objects li.toctree-l1 > a")
-	err = processClasses(nodes, "Object")
+	nodes = classSectionLinks(doc, "other-objects")
+	err = processClasses(ctx, nodes, "Object")
 	if err != nil {
 		return err
 	}
 
 	// types
-	nodes = doc.Find("section#variant-types li.toctree-l1 > a")
-	err = processClasses(nodes, "Type")
+	nodes = classSectionLinks(doc, "variant-types")
+	err = processClasses(ctx, nodes, "Type")
 	if err != nil {
 		return err
 	}
@@ -265,11 +596,12 @@ func processClassesIndex() (err error) {
 	return nil
 }
 
-func processClasses(sel *goquery.Selection, etype string) error {
+func processClasses(ctx context.Context, sel *goquery.Selection, etype string) error {
 	type inputData struct {
 		FilePath string
 		HRef     string
 		Sel      *goquery.Selection
+		Size     int64 // size of the input file, used to schedule the largest files first
 	}
 
 	var classes []inputData
@@ -278,6 +610,10 @@ func processClasses(sel *goquery.Selection, etype string) error {
 		if !ok {
 			return
 		}
+		// the GDScript globals do not apply to C#
+		if samples == samplesCSharp && s.Text() == "@GDScript" {
+			return
+		}
 
 		fileUrl, err := url.Parse(ref)
 		if err != nil {
@@ -286,19 +622,26 @@ func processClasses(sel *goquery.Selection, etype string) error {
 		}
 		// prefix the class with "classes/"
 		fileUrl.Path = filepath.Join("classes", fileUrl.Path)
+		if !includePath(fileUrl.Path) {
+			return
+		}
 		// update ref variable
 		ref = fileUrl.String()
 		classes = append(classes, inputData{
 			FilePath: fileUrl.Path,
 			HRef:     ref,
 			Sel:      s,
+			Size:     fileSize(filepath.Join(docsPath, fileUrl.Path)),
 		})
 	})
 
 	type class struct {
-		Name string
-		Path string
-		Rows []SearchIndex
+		Name   string
+		Path   string
+		Parent string
+		Status apiStatus
+		Brief  string // Brief is the brief description of the class, set with --format=man
+		Rows   []SearchIndex
 	}
 
 	classData := make([]class, len(classes))
@@ -324,7 +667,7 @@ func processClasses(sel *goquery.Selection, etype string) error {
 	)
 
 	// Process all classes
-	err := parallel.For(len(classes), func(i, _ int) error {
+	err := executor.ForWeightedWithContext(ctx, len(classes), func(i int) int64 { return classes[i].Size }, func(_ context.Context, i, _ int) error {
 		data := &classes[i]
 		cd := &classData[i]
 		{
@@ -351,8 +694,9 @@ func processClasses(sel *goquery.Selection, etype string) error {
 
 		// Class name
 		var className string
+		title := doc.FindMatcher(selTitle).First()
 		{
-			n := doc.FindMatcher(selTitle).First()
+			n := title
 			className = strings.TrimRight(n.Text(), "¶\uF0C1")
 			link, a, _ := newSectionHeaderLink(className, "Class")
 			headNode.AppendChild(link)
@@ -361,6 +705,17 @@ func processClasses(sel *goquery.Selection, etype string) error {
 			link, a, _ = newSectionItemLink(className, "Class")
 			headNode.AppendChild(link)
 			n.Get(0).Parent.InsertBefore(a, n.Get(0))
+
+			cd.Parent = classParent(n)
+			cd.Status = classStatus(n)
+		}
+
+		// members of a deprecated or experimental class share its status, unless noted otherwise
+		status := func(n *goquery.Selection) apiStatus {
+			if st := memberStatus(n); st != statusStable {
+				return st
+			}
+			return cd.Status
 		}
 
 		// Description
@@ -409,15 +764,12 @@ func processClasses(sel *goquery.Selection, etype string) error {
 							if desc.Length() > 0 {
 								s = s.Parent() // we want the complete text
 								itemName := s.Text()
-								link, a, target := newSectionItemLink(itemName, etype)
+								st := status(desc)
+								link, a, target := newSectionItemLink(tocName(itemName, st), etype)
 								headNode.AppendChild(link)
 								desc.Get(0).Parent.InsertBefore(a, desc.Get(0))
 
-								cd.Rows = append(cd.Rows, SearchIndex{
-									Name: itemName,
-									Type: etype,
-									Path: makeSearchIndexPath(data.FilePath, itemName, itemName, className, target),
-								})
+								cd.Rows = append(cd.Rows, newMemberRow(data.FilePath, itemName, etype, className, target, st))
 							}
 						}
 					})
@@ -446,14 +798,11 @@ func processClasses(sel *goquery.Selection, etype string) error {
 						return
 					}
 
-					link, a, target := newSectionItemLink(signalName, "Signal")
+					st := status(s)
+					link, a, target := newSectionItemLink(tocName(signalName, st), "Signal")
 					headNode.AppendChild(link)
 					s.Get(0).Parent.InsertBefore(a, s.Get(0))
-					cd.Rows = append(cd.Rows, SearchIndex{
-						Name: signalName,
-						Type: "Signal",
-						Path: makeSearchIndexPath(data.FilePath, signalName, signalName, className, target),
-					})
+					cd.Rows = append(cd.Rows, newMemberRow(data.FilePath, signalName, "Signal", className, target, st))
 				})
 			}
 		}
@@ -479,14 +828,11 @@ func processClasses(sel *goquery.Selection, etype string) error {
 						return
 					}
 
-					link, a, target := newSectionItemLink(enumName, "Enum")
+					st := status(s)
+					link, a, target := newSectionItemLink(tocName(enumName, st), "Enum")
 					headNode.AppendChild(link)
 					s.Get(0).Parent.InsertBefore(a, s.Get(0))
-					cd.Rows = append(cd.Rows, SearchIndex{
-						Name: enumName,
-						Type: "Enum",
-						Path: makeSearchIndexPath(data.FilePath, enumName, enumName, className, target),
-					})
+					cd.Rows = append(cd.Rows, newMemberRow(data.FilePath, enumName, "Enum", className, target, st))
 
 					// now find all enum variants
 					constants := s.Parent().Find(fmt.Sprintf("p.classref-enumeration-constant > a[href=\"#%s\"]", id))
@@ -498,14 +844,11 @@ func processClasses(sel *goquery.Selection, etype string) error {
 						}
 						constantName = enumName + "." + constantName
 
-						link, a, target := newSectionItemLink(constantName, "Enum")
+						st := status(s.Parent())
+						link, a, target := newSectionItemLink(tocName(constantName, st), "Enum")
 						headNode.AppendChild(link)
-						nameNode.Get(0).InsertBefore(a, nameNode.Get(0))
-						cd.Rows = append(cd.Rows, SearchIndex{
-							Name: constantName,
-							Type: "Enum",
-							Path: makeSearchIndexPath(data.FilePath, constantName, constantName, className, target),
-						})
+						nameNode.Get(0).Parent.InsertBefore(a, nameNode.Get(0))
+						cd.Rows = append(cd.Rows, newMemberRow(data.FilePath, constantName, "Enum", className, target, st))
 					})
 				})
 			}
@@ -526,20 +869,19 @@ func processClasses(sel *goquery.Selection, etype string) error {
 						return
 					}
 
-					link, a, target := newSectionItemLink(constantName, "Constant")
+					st := status(s)
+					link, a, target := newSectionItemLink(tocName(constantName, st), "Constant")
 					headNode.AppendChild(link)
 					s.Get(0).Parent.InsertBefore(a, s.Get(0))
-					cd.Rows = append(cd.Rows, SearchIndex{
-						Name: constantName,
-						Type: "Constant",
-						Path: makeSearchIndexPath(data.FilePath, constantName, constantName, className, target),
-					})
+					cd.Rows = append(cd.Rows, newMemberRow(data.FilePath, constantName, "Constant", className, target, st))
 				})
 			}
 		}
 
-		writeRows(cd.Rows)
-
+		if format == formatMan {
+			cd.Brief = classBrief(title)
+			return writeManPage(className, cd.Brief, title, doc, cd.Rows)
+		}
 		return writeHTML(filepath.Join(targetPath, data.FilePath), top, doc)
 	})
 
@@ -548,19 +890,24 @@ func processClasses(sel *goquery.Selection, etype string) error {
 	}
 
 	rows := lo.Map(classData, func(c class, i int) SearchIndex {
-		return SearchIndex{
-			Name: c.Name,
-			Type: etype,
-			Path: c.Path,
-		}
+		return newClassRow(c.Path, c.Name, etype, c.Status)
 	})
+	for _, c := range classData {
+		rows = append(rows, c.Rows...)
+		knownClasses[c.Name] = struct{}{}
+		hierarchy.add(c.Name, c.Parent, c.Path, c.Rows)
+		if format == formatMan {
+			manPages[c.Name] = c.Brief
+		}
+	}
+	rows = expandMemberRows(rows)
 
 	writeRows(rows)
 
 	return nil
 }
 
-func processGuides(root *html.Node) error {
+func processGuides(ctx context.Context, root *html.Node) error {
 	sel := css.MustCompile("li.toctree-l1 > a, li.toctree-l2 > a, li.toctree-l3 > a")
 	doc := goquery.NewDocumentFromNode(root)
 
@@ -569,6 +916,7 @@ func processGuides(root *html.Node) error {
 		GroupTitle string // set if this document is part of a group
 		FilePath   string
 		HRef       string
+		Size       int64 // size of the input file, used to schedule the largest files first
 	}
 
 	// docFileSet is the unique set of all documents to process
@@ -605,10 +953,16 @@ func processGuides(root *html.Node) error {
 
 		pathTitleMap[fileUrl.Path] = title
 
+		// excluded documents still name their group
+		if !includePath(fileUrl.Path) {
+			return
+		}
+
 		input = append(input, document{
 			Title:    title,
 			FilePath: fileUrl.Path,
 			HRef:     fileUrl.String(),
+			Size:     fileSize(filepath.Join(docsPath, fileUrl.Path)),
 		})
 	})
 
@@ -628,7 +982,12 @@ func processGuides(root *html.Node) error {
 		sectionHeader = css.MustCompile("section > h2")
 	)
 
-	err := parallel.For(len(input), func(i, _ int) error {
+	var index codeIndex
+	if codeLinks > 0 {
+		index = newCodeIndex()
+	}
+
+	err := executor.ForWeightedWithContext(ctx, len(input), func(i int) int64 { return input[i].Size }, func(_ context.Context, i, _ int) error {
 		data := &input[i]
 		slog.Info("Processing file.", "guide", data.Title, "group", data.GroupTitle, "path", data.FilePath)
 
@@ -664,7 +1023,19 @@ func processGuides(root *html.Node) error {
 			s.Get(0).Parent.InsertBefore(a, s.Get(0))
 		})
 
-		return writeHTML(filepath.Join(targetPath, data.FilePath), top, doc)
+		if index != nil {
+			linkCode(doc, data.FilePath, index, codeLinks)
+		}
+
+		if err = writeHTML(filepath.Join(targetPath, data.FilePath), top, doc); err != nil {
+			return err
+		}
+		// the links are recorded once the page is written, as links to the online
+		// documentation may have been rewritten to the class pages
+		if backlinks {
+			references.add(data.FilePath, data.Title, doc)
+		}
+		return nil
 	})
 
 	if err != nil {
@@ -684,6 +1055,15 @@ func processGuides(root *html.Node) error {
 	return nil
 }
 
+// fileSize returns the size of the file at path, or 0 if it cannot be determined.
+func fileSize(path string) int64 {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return fi.Size()
+}
+
 func newSectionHeaderLink(name, etype string) (headLink *html.Node, a *html.Node, target string) {
 	return newSectionLink(name, etype, true)
 }
@@ -693,12 +1073,7 @@ func newSectionItemLink(name, etype string) (headLink *html.Node, a *html.Node,
 }
 
 func newSectionLink(name, etype string, isSectionHeader bool) (headLink *html.Node, a *html.Node, target string) {
-	name = strings.Replace(url.QueryEscape(name), "+", "%20", -1)
-	var isSection int
-	if isSectionHeader {
-		isSection = 1
-	}
-	target = fmt.Sprintf("//dash_ref/%s/%s/%d", etype, name, isSection)
+	target = sectionTarget(name, etype, isSectionHeader)
 
 	return &html.Node{
 			Type:     html.ElementNode,
@@ -718,6 +1093,17 @@ func newSectionLink(name, etype string, isSectionHeader bool) (headLink *html.No
 		}, target
 }
 
+// sectionTarget returns the dashtoc reference used by both the <link> in the head and
+// the anchor in the body of a page.
+func sectionTarget(name, etype string, isSectionHeader bool) string {
+	name = strings.Replace(url.QueryEscape(name), "+", "%20", -1)
+	var isSection int
+	if isSectionHeader {
+		isSection = 1
+	}
+	return fmt.Sprintf("//dash_ref/%s/%s/%d", etype, name, isSection)
+}
+
 func makeSearchIndexPath(docPath, entryName, origName, desc, target string) string {
 	entryName = url.PathEscape(entryName)
 	origName = url.PathEscape(origName)
@@ -752,19 +1138,19 @@ var devCss = `
 }
 `
 
-var infoPlist = `
+var infoPlist = template.Must(template.New("Info.plist").Parse(`
 <?xml version="1.0" encoding="UTF-8"?>
 <!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
 <plist version="1.0">
 <dict>
 	<key>CFBundleIdentifier</key>
-	<string>godot</string>
+	<string>{{.BundleID}}</string>
 	<key>CFBundleName</key>
-	<string>Godot</string>
+	<string>{{.BundleName}}</string>
 	<key>DocSetPlatformFamily</key>
 	<string>godot</string>
 	<key>DashDocSetFallbackURL</key>
-	<string>https://docs.godotengine.org/en/stable/</string>
+	<string>{{.DocsURL}}</string>
 	<key>DashDocSetFamily</key>
 	<string>dashtoc3</string>
 	<key>isDashDocset</key>
@@ -775,14 +1161,28 @@ var infoPlist = `
 	<string>index.html</string>
 </dict>
 </plist>
-`
+`))
 
 func writeHTML(dest string, root *html.Node, doc *goquery.Document) error {
 	cleanupDocument(root, doc)
+	if samples != samplesTabs {
+		selectCodeSamples(doc)
+	}
+	if offline {
+		docPath, _ := filepath.Rel(targetPath, dest)
+		rewriteOffline(filepath.ToSlash(docPath), root, doc)
+	}
+	if format == formatMarkdown {
+		return writeMarkdownPage(dest, doc)
+	}
+	return renderHTML(dest, root)
+}
 
+// renderHTML writes the document at root to dest.
+func renderHTML(dest string, root *html.Node) error {
 	dir := filepath.Dir(dest)
 	_ = os.MkdirAll(dir, 0755)
-	out, err := os.Create(dest)
+	out, err := createFile(dest)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"html/template"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// hierarchyPath is the path of the class hierarchy page in the docset.
+const hierarchyPath = "classes/hierarchy.html"
+
+const hierarchyTitle = "Class Hierarchy"
+
+// hierarchyNode is a class of the hierarchy page, with its subclasses.
+type hierarchyNode struct {
+	Name     string
+	Href     string
+	Target   string // Target is the dashtoc anchor of a class with subclasses
+	Open     bool
+	Children []*hierarchyNode
+}
+
+type hierarchyPage struct {
+	Title  string
+	Target string
+	Links  []string
+	Roots  []*hierarchyNode
+}
+
+// buildHierarchyPage builds the tree of every class of the docset. Classes with an
+// undocumented parent are shown as roots, as is the first class, by name, of an
+// inheritance cycle, whose subclasses would otherwise never be reached from a root.
+func (h *classHierarchy) buildHierarchyPage() *hierarchyPage {
+	p := &hierarchyPage{
+		Title:  hierarchyTitle,
+		Target: sectionTarget(hierarchyTitle, "Section", true),
+	}
+	p.Links = append(p.Links, p.Target)
+
+	nodes := make(map[string]*hierarchyNode, len(h.parents))
+	for name, path := range h.paths {
+		nodes[name] = &hierarchyNode{Name: name, Href: strings.TrimPrefix(path, "classes/")}
+	}
+
+	names := lo.Keys(h.parents)
+	slices.Sort(names)
+
+	// the classes whose parent is ignored to break an inheritance cycle
+	cycleRoots := make(map[string]struct{})
+	parentOf := func(name string) string {
+		if _, ok := cycleRoots[name]; ok {
+			return ""
+		}
+		return h.parents[name]
+	}
+	for _, name := range names {
+		p := parentOf(name)
+		for i := 0; p != "" && p != name && i < len(names); i++ {
+			p = parentOf(p)
+		}
+		if p == name {
+			slog.Warn("Class inheritance cycle, the class is shown as a root.", "class", name, "parent", h.parents[name])
+			cycleRoots[name] = struct{}{}
+		}
+	}
+
+	for _, name := range names {
+		n := nodes[name]
+		if parent, ok := nodes[parentOf(name)]; ok {
+			parent.Children = append(parent.Children, n)
+		} else {
+			n.Open = true
+			p.Roots = append(p.Roots, n)
+		}
+	}
+
+	// classes with subclasses are listed in the table of contents
+	for _, name := range names {
+		if n := nodes[name]; len(n.Children) > 0 {
+			n.Target = sectionTarget(name, "Class", false)
+			p.Links = append(p.Links, n.Target)
+		}
+	}
+	return p
+}
+
+// writeHierarchyPage writes the class hierarchy page and its search index row.
+func writeHierarchyPage() error {
+	slog.Info("Write class hierarchy.", "classes", len(hierarchy.parents))
+
+	var buf bytes.Buffer
+	if err := hierarchyTemplate.Execute(&buf, hierarchy.buildHierarchyPage()); err != nil {
+		return errors.Wrap(err, "failed to render the class hierarchy")
+	}
+	if err := writeFile(filepath.Join(targetPath, hierarchyPath), buf.Bytes()); err != nil {
+		return err
+	}
+
+	writeRows([]SearchIndex{{
+		Name: hierarchyTitle,
+		Type: "Guide",
+		Path: makeSearchIndexPath(hierarchyPath, hierarchyTitle, hierarchyTitle, "Classes", ""),
+	}})
+	return nil
+}
+
+const hierarchyStyle = `
+ul.hierarchy, ul.hierarchy ul { list-style: none; padding-left: 1.2em; }
+ul.hierarchy details > summary { cursor: pointer; }
+ul.hierarchy li > a { margin-left: 1em; }
+`
+
+var hierarchyTemplate = template.Must(template.New("hierarchy").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>` + xmlPageStyle + hierarchyStyle + `</style>
+{{range .Links}}<link href="{{.}}">
+{{end}}</head>
+<body>
+<a class="dashAnchor" name="{{.Target}}"></a><h1>{{.Title}}</h1>
+<ul class="hierarchy">
+{{range .Roots}}{{template "node" .}}{{end}}</ul>
+</body>
+</html>
+{{define "node"}}<li>{{if .Children}}<details{{if .Open}} open{{end}}><summary><a class="dashAnchor" name="{{.Target}}"></a><a class="reference internal" href="{{.Href}}">{{.Name}}</a></summary>
+<ul>
+{{range .Children}}{{template "node" .}}{{end}}</ul>
+</details>{{else}}<a class="reference internal" href="{{.Href}}">{{.Name}}</a>{{end}}</li>
+{{end}}`))
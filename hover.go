@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"golang.org/x/net/html"
+)
+
+// The types in this file model the hover database written with --hover-json, which editor
+// tooling, such as a GDScript language server, loads to show the documentation of the API.
+
+type hoverDatabase struct {
+	Version string                 `json:"version,omitempty"`
+	DocsURL string                 `json:"docs_url"`
+	Classes map[string]*hoverClass `json:"classes"`
+}
+
+type hoverClass struct {
+	Name        string        `json:"name"`
+	Inherits    string        `json:"inherits,omitempty"`
+	Description string        `json:"description"`
+	Markdown    string        `json:"markdown"`
+	URL         string        `json:"url"`
+	Path        string        `json:"path"` // Path is the path of the class page in the docset
+	Members     []hoverMember `json:"members"`
+}
+
+type hoverMember struct {
+	Name        string `json:"name"` // Name is the name of the member, without its signature
+	Type        string `json:"type"`
+	Signature   string `json:"signature"`
+	Status      string `json:"status,omitempty"`
+	Description string `json:"description"`
+	Markdown    string `json:"markdown"`
+	URL         string `json:"url"`
+	Path        string `json:"path"`
+}
+
+// writeHoverDatabase writes the descriptions, signatures and documentation URLs of every
+// class and member of the docset to dest, reading them from the class pages of the docset.
+func writeHoverDatabase(ctx context.Context, dest string) error {
+	slog.Info("Write hover database.", "path", dest)
+
+	docsURL := bundle{Lang: lang, Version: version}.DocsURL()
+	classes := lo.Keys(hierarchy.paths)
+	slices.Sort(classes)
+
+	entries := make([]*hoverClass, len(classes))
+	err := executor.ForWithContext(ctx, len(classes), func(_ context.Context, i, _ int) error {
+		c, err := readHoverClass(classes[i], docsURL)
+		entries[i] = c
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	db := hoverDatabase{
+		Version: version,
+		DocsURL: docsURL,
+		Classes: make(map[string]*hoverClass, len(entries)),
+	}
+	for _, c := range entries {
+		db.Classes[c.Name] = c
+	}
+	b, err := json.MarshalIndent(db, "", "\t")
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(writeFile(dest, append(b, '\n')), "failed to write hover database")
+}
+
+// readHoverClass reads the description of a class and its members from the class page.
+func readHoverClass(name, docsURL string) (*hoverClass, error) {
+	docPath, _, _ := strings.Cut(hierarchy.paths[name], "#")
+	f, err := os.Open(filepath.Join(targetPath, docPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	root, err := html.Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+	doc := goquery.NewDocumentFromNode(root)
+
+	pageURL := docsURL + docPath
+	desc := classDescription(doc)
+	c := &hoverClass{
+		Name:        name,
+		Inherits:    hierarchy.parents[name],
+		Description: plainText(desc),
+		Markdown:    markdownText(desc, pageURL),
+		URL:         pageURL,
+		Path:        docPath,
+		Members:     make([]hoverMember, 0, len(hierarchy.members[name])),
+	}
+
+	for _, row := range hierarchy.members[name] {
+		anchor := doc.Find(`a.dashAnchor[name="` + row.Member.Target + `"]`).First()
+		sel := memberDescription(anchor)
+		if sel.Length() == 0 {
+			slog.Warn("Member description not found.", "class", name, "member", row.Name)
+			continue
+		}
+
+		sig := sel.First()
+		id, ok := sig.Attr("id")
+		if !ok {
+			// the signatures of pages built from the class XML are in an element with the id
+			id, _ = sig.Parent().Attr("id")
+		}
+		memberName, _, _ := strings.Cut(row.Name, "(")
+		body := sel.Slice(1, sel.Length())
+		c.Members = append(c.Members, hoverMember{
+			Name:        strings.TrimSpace(memberName),
+			Type:        row.Type,
+			Signature:   plainText(sig),
+			Status:      string(row.Status),
+			Description: plainText(body),
+			Markdown:    markdownText(body, pageURL),
+			URL:         pageURL + "#" + id,
+			Path:        docPath + "#" + row.Member.Target,
+		})
+	}
+	return c, nil
+}
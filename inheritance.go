@@ -0,0 +1,118 @@
+package main
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/samber/lo"
+	"golang.org/x/net/html"
+)
+
+// classHierarchy records the parent and members of every class of the docset, for the
+// passes that need every class, such as indexing inherited members.
+type classHierarchy struct {
+	parents map[string]string
+	paths   map[string]string // paths are the paths of the class pages
+	members map[string][]SearchIndex
+}
+
+func newClassHierarchy() *classHierarchy {
+	return &classHierarchy{
+		parents: make(map[string]string),
+		paths:   make(map[string]string),
+		members: make(map[string][]SearchIndex),
+	}
+}
+
+// hierarchy is the class hierarchy of the docset being built
+var hierarchy = newClassHierarchy()
+
+// add records a class with its parent, which is empty for a root class, the path of its
+// page and the search index rows of its members.
+func (h *classHierarchy) add(name, parent, docPath string, rows []SearchIndex) {
+	h.parents[name] = parent
+	h.paths[name] = docPath
+	h.members[name] = lo.Filter(rows, func(r SearchIndex, _ int) bool { return r.Member != nil })
+}
+
+// ancestors returns the ancestors of a class, starting with its parent.
+func (h *classHierarchy) ancestors(name string) []string {
+	var names []string
+	for p := h.parents[name]; p != "" && !slices.Contains(names, p) && p != name; p = h.parents[p] {
+		names = append(names, p)
+	}
+	return names
+}
+
+// classParent returns the parent of the class with the title h1, or an empty string for
+// a root class. The label of the paragraph listing the ancestors of the class, e.g.
+// "Inherits: Node2D < CanvasItem < Node < Object", is translated, so the paragraph is the
+// first labelled paragraph linking to classes, unless the links are separated by commas,
+// as are the subclasses listed by "Inherited By:".
+func classParent(h1 *goquery.Selection) string {
+	var parent string
+	h1.NextAllFiltered("p").EachWithBreak(func(_ int, p *goquery.Selection) bool {
+		links := p.Find("a")
+		if !isLabelled(p) || links.Length() == 0 {
+			return true
+		}
+		if !isSubclassList(p) {
+			parent = strings.TrimSpace(links.First().Text())
+		}
+		return false
+	})
+	return parent
+}
+
+// isSubclassList reports whether the labelled paragraph p lists classes separated by commas,
+// as the subclasses of a class are.
+func isSubclassList(p *goquery.Selection) bool {
+	for n := p.Get(0).FirstChild; n != nil; n = n.NextSibling {
+		if n.Type == html.TextNode && strings.Contains(n.Data, ",") {
+			return true
+		}
+	}
+	return false
+}
+
+// inheritedRows returns a row for each member a class inherits, named <class>.<member> and
+// linked to the member on the page of the class declaring it. Like aliases, the rows have
+// the name of the member as their original name. Members of a class in skip are not
+// inherited, such as those of Object, which every class inherits.
+func (h *classHierarchy) inheritedRows(skip []string) []SearchIndex {
+	classes := lo.Keys(h.parents)
+	slices.Sort(classes)
+
+	var rows []SearchIndex
+	for _, class := range classes {
+		// members declared closest to the class override those of its ancestors
+		declared := make(map[string]struct{})
+		key := func(r SearchIndex) string { return r.Type + "\x00" + r.Name }
+		for _, r := range h.members[class] {
+			declared[key(r)] = struct{}{}
+		}
+
+		for _, ancestor := range h.ancestors(class) {
+			if slices.Contains(skip, ancestor) {
+				continue
+			}
+			for _, r := range h.members[ancestor] {
+				if _, ok := declared[key(r)]; ok {
+					continue
+				}
+				declared[key(r)] = struct{}{}
+
+				name := class + "." + strings.TrimSpace(r.Name)
+				rows = append(rows, SearchIndex{
+					Name:   name,
+					Type:   r.Type,
+					Path:   makeSearchIndexPath(r.Member.DocPath, name, r.Name, menuDescription("inherited from "+ancestor, r.Status), r.Member.Target),
+					Member: r.Member,
+					Status: r.Status,
+				})
+			}
+		}
+	}
+	return rows
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// classPage returns a class page with the notes given before its sections.
+func classPage(t *testing.T, notes string) *goquery.Document {
+	t.Helper()
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<html><body><section id="class-x"><h1>X</h1>` + notes +
+		`<section id="description"><h2>Description</h2><p>A class.</p></section></section></body></html>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func classLink(name string) string {
+	return `<a class="reference internal" href="class_` + strings.ToLower(name) + `.html"><span class="std std-ref">` + name + `</span></a>`
+}
+
+func TestClassParent(t *testing.T) {
+	tests := []struct {
+		name  string
+		notes string
+		want  string
+	}{
+		{"english", `<p><strong>Inherits:</strong> ` + classLink("Node2D") + ` &lt; ` + classLink("Node") + `</p>` +
+			`<p><strong>Inherited By:</strong> ` + classLink("Sprite2D") + `, ` + classLink("Camera2D") + `</p>`, "Node2D"},
+		{"french", `<p><strong>Hérite de :</strong> ` + classLink("Node2D") + ` &lt; ` + classLink("Node") + `</p>`, "Node2D"},
+		{"chinese", `<p><strong>继承：</strong> ` + classLink("Object") + `</p>`, "Object"},
+		{"only subclasses", `<p><strong>Hérité par :</strong> ` + classLink("Node") + `, ` + classLink("Resource") + `</p>`, ""},
+		{"brief first", `<p>A brief with a ` + classLink("Node") + `.</p>` +
+			`<p><strong>Inherits:</strong> ` + classLink("Object") + `</p>`, "Object"},
+		{"no notes", `<p>A brief.</p>`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h1 := classPage(t, tt.notes).Find("h1")
+			if got := classParent(h1); got != tt.want {
+				t.Errorf("classParent() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassStatus(t *testing.T) {
+	tests := []struct {
+		name  string
+		notes string
+		want  apiStatus
+	}{
+		{"english deprecated", `<p><strong>Deprecated:</strong> Use Y.</p>`, statusDeprecated},
+		{"english experimental", `<div class="admonition"><p><strong>Experimental:</strong> May change.</p></div>`, statusExperimental},
+		{"translated deprecated", `<div class="contextual-bg bg-danger docutils container"><p><strong>Obsolète :</strong> Utilisez Y.</p></div>`, statusDeprecated},
+		{"translated experimental", `<div class="contextual-bg bg-warning docutils container"><p><strong>实验性：</strong> 可能会变。</p></div>`, statusExperimental},
+		{"label inside a sentence", `<div class="contextual-bg bg-danger docutils container"><p>Voir <strong>Y :</strong> ici.</p></div>`, statusStable},
+		{"stable", `<p><strong>Inherits:</strong> ` + classLink("Object") + `</p><p>A brief.</p>`, statusStable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h1 := classPage(t, tt.notes).Find("h1")
+			if got := classStatus(h1); got != tt.want {
+				t.Errorf("classStatus() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
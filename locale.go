@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	css "github.com/andybalholm/cascadia"
+	"github.com/samber/lo"
+)
+
+// locale describes a language of the translated Godot documentation.
+type locale struct {
+	Code string // Code is the language code used by the documentation, e.g. zh_CN
+	Name string // Name is the native name of the language
+}
+
+// locales are the languages the Godot documentation is translated to.
+var locales = map[string]locale{
+	"en":    {Code: "en", Name: "English"},
+	"de":    {Code: "de", Name: "Deutsch"},
+	"es":    {Code: "es", Name: "Español"},
+	"fr":    {Code: "fr", Name: "Français"},
+	"it":    {Code: "it", Name: "Italiano"},
+	"ja":    {Code: "ja", Name: "日本語"},
+	"ko":    {Code: "ko", Name: "한국어"},
+	"pl":    {Code: "pl", Name: "Polski"},
+	"pt_BR": {Code: "pt_BR", Name: "Português (Brasil)"},
+	"ru":    {Code: "ru", Name: "Русский"},
+	"uk":    {Code: "uk", Name: "Українська"},
+	"zh_CN": {Code: "zh_CN", Name: "简体中文"},
+	"zh_TW": {Code: "zh_TW", Name: "繁體中文"},
+}
+
+// lookupLocale returns the locale for a language code.
+func lookupLocale(code string) (locale, error) {
+	l, ok := locales[code]
+	if !ok {
+		codes := lo.Keys(locales)
+		slices.Sort(codes)
+		return locale{}, fmt.Errorf("unknown language %q, expected one of: %s", code, strings.Join(codes, ", "))
+	}
+	return l, nil
+}
+
+func (l locale) isEnglish() bool {
+	return l.Code == "en"
+}
+
+// urlCode returns the language as used by the URLs of ReadTheDocs, e.g. zh-cn.
+func (l locale) urlCode() string {
+	return strings.ReplaceAll(strings.ToLower(l.Code), "_", "-")
+}
+
+// classSection is a section of classes/index.html listing classes.
+type classSection struct {
+	ID    string // ID is the id of the section in the English documentation
+	Label string // Label is the explicit label of the section, which is not translated
+}
+
+// classSections are the sections of classes/index.html listing the classes, in order.
+// Sphinx derives the section ids from the translated titles, but keeps the labels as the
+// ids of <span> elements at the start of each section.
+var classSections = []classSection{
+	{ID: "globals", Label: "toc-class-ref-globals"},
+	{ID: "nodes", Label: "toc-class-ref-nodes"},
+	{ID: "resources", Label: "toc-class-ref-resources"},
+	{ID: "other-objects", Label: "toc-class-ref-others"},
+	{ID: "variant-types", Label: "toc-class-ref-variants"},
+}
+
+// selClassLinks selects the links to the class pages in a section of classes/index.html.
+var selClassLinks = css.MustCompile("li.toctree-l1 > a")
+
+// classSectionLinks returns the links to the class pages in a section of classes/index.html,
+// where id is the id of the section in the English documentation. The section is found by
+// its label, its English id, or else its position among the sections listing classes, so
+// that it is found in the documentation of any language.
+func classSectionLinks(doc *goquery.Document, id string) *goquery.Selection {
+	i := slices.IndexFunc(classSections, func(s classSection) bool { return s.ID == id })
+	if i < 0 {
+		return doc.Find("section#" + id).FindMatcher(selClassLinks)
+	}
+	section := doc.Find("span#" + classSections[i].Label).Parent().Filter("section")
+	if section.Length() == 0 {
+		section = doc.Find("section#" + id)
+	}
+	if section.Length() == 0 {
+		// the innermost sections with class links, when there is one for each class section
+		sections := doc.Find("section").FilterFunction(func(_ int, s *goquery.Selection) bool {
+			return s.FindMatcher(selClassLinks).Length() > 0 && s.Find("section").Length() == 0
+		})
+		if sections.Length() == len(classSections) {
+			section = sections.Eq(i)
+		}
+	}
+	return section.FindMatcher(selClassLinks)
+}
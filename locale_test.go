@@ -0,0 +1,94 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// classIndex returns a classes/index.html with a section for each class section, from the
+// ids, labels and class names given for each.
+func classIndex(t *testing.T, sections [][3]string) *goquery.Document {
+	t.Helper()
+	var sb strings.Builder
+	sb.WriteString(`<html><body><div role="main"><section id="toutes-les-classes"><h1>Toutes les classes</h1>`)
+	for _, s := range sections {
+		sb.WriteString(`<section id="` + s[0] + `">`)
+		if s[1] != "" {
+			sb.WriteString(`<span id="` + s[1] + `"></span>`)
+		}
+		sb.WriteString(`<h2>` + s[0] + `</h2><div class="toctree-wrapper compound"><ul>`)
+		sb.WriteString(`<li class="toctree-l1"><a class="reference internal" href="class_` + strings.ToLower(s[2]) + `.html">` + s[2] + `</a></li>`)
+		sb.WriteString(`</ul></div></section>`)
+	}
+	sb.WriteString(`</section></div></body></html>`)
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(sb.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestClassSectionLinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		sections [][3]string
+	}{
+		{"english", [][3]string{
+			{"globals", "", "@GlobalScope"},
+			{"nodes", "", "Node"},
+			{"resources", "", "Resource"},
+			{"other-objects", "", "Object"},
+			{"variant-types", "", "Vector2"},
+		}},
+		{"translated with labels", [][3]string{
+			{"globales", "toc-class-ref-globals", "@GlobalScope"},
+			{"noeuds", "toc-class-ref-nodes", "Node"},
+			{"ressources", "toc-class-ref-resources", "Resource"},
+			{"autres-objets", "toc-class-ref-others", "Object"},
+			{"types-variant", "toc-class-ref-variants", "Vector2"},
+		}},
+		// the sections are found by their position
+		{"translated without labels", [][3]string{
+			{"globales", "", "@GlobalScope"},
+			{"noeuds", "", "Node"},
+			{"ressources", "", "Resource"},
+			{"autres-objets", "", "Object"},
+			{"types-variant", "", "Vector2"},
+		}},
+		// a translated id equal to the English id of another section
+		{"labels before ids", [][3]string{
+			{"globals", "toc-class-ref-globals", "@GlobalScope"},
+			{"resources", "toc-class-ref-nodes", "Node"},
+			{"nodes", "toc-class-ref-resources", "Resource"},
+			{"other-objects", "toc-class-ref-others", "Object"},
+			{"variant-types", "toc-class-ref-variants", "Vector2"},
+		}},
+	}
+	want := map[string]string{
+		"globals":       "@GlobalScope",
+		"nodes":         "Node",
+		"resources":     "Resource",
+		"other-objects": "Object",
+		"variant-types": "Vector2",
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := classIndex(t, tt.sections)
+			for _, s := range classSections {
+				links := classSectionLinks(doc, s.ID)
+				if links.Length() != 1 || links.Text() != want[s.ID] {
+					t.Errorf("classSectionLinks(%q) = %q, want %q", s.ID, links.Text(), want[s.ID])
+				}
+			}
+		})
+	}
+
+	t.Run("unknown layout", func(t *testing.T) {
+		doc := classIndex(t, [][3]string{{"globales", "", "@GlobalScope"}, {"noeuds", "", "Node"}})
+		if links := classSectionLinks(doc, "nodes"); links.Length() != 0 {
+			t.Errorf("classSectionLinks(nodes) = %q, want no links", links.Text())
+		}
+	})
+}
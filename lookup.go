@@ -0,0 +1,199 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/html"
+)
+
+var (
+	lookupCmd = &cobra.Command{
+		Use:   "lookup QUERY",
+		Short: "Search the index of a docset from the terminal",
+		Long: `Search the index of a docset the way Dash does, printing the best matches with the path
+and anchor of their page. Names match exactly, by prefix, by substring or fuzzily, in that order.`,
+		Args:         cobra.ExactArgs(1),
+		RunE:         lookup,
+		SilenceUsage: true,
+	}
+	// arguments
+	lookupTypes    []string
+	lookupLimit    int
+	lookupDescribe bool
+)
+
+func init() {
+	lookupCmd.Flags().StringVar(&docsetPath, "docset-path", "", "The base path to the Godot.docset")
+	lookupCmd.Flags().StringSliceVarP(&lookupTypes, "type", "t", nil, "Only show entries of this type, e.g. Method or Signal (repeatable)")
+	lookupCmd.Flags().IntVarP(&lookupLimit, "limit", "n", 10, "The maximum number of entries to show")
+	lookupCmd.Flags().BoolVar(&lookupDescribe, "describe", false, "Print the description of each entry as plain text")
+	_ = cobra.MarkFlagRequired(lookupCmd.Flags(), "docset-path")
+	cmd.AddCommand(lookupCmd)
+}
+
+// lookupHit is a search index row matching the query.
+type lookupHit struct {
+	Row     SearchIndex
+	Score   int
+	Name    string // Name is the name of the row, without the signature of a method
+	Desc    string // Desc is the menu description, usually the class of a member
+	DocPath string
+	Target  string
+}
+
+func lookup(cmd *cobra.Command, args []string) error {
+	if lookupLimit < 1 {
+		return fmt.Errorf("--limit must be at least 1, got %d", lookupLimit)
+	}
+	rows, err := readSearchIndex(filepath.Join(docsetPath, "Contents/Resources/docSet.dsidx"))
+	if err != nil {
+		return err
+	}
+
+	hits := searchRows(rows, args[0], lookupTypes)
+	if len(hits) > lookupLimit {
+		hits = hits[:lookupLimit]
+	}
+	if len(hits) == 0 {
+		return fmt.Errorf("no entries match %q", args[0])
+	}
+
+	out := cmd.OutOrStdout()
+	if !lookupDescribe {
+		tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		for _, h := range hits {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", h.Row.Type, strings.TrimSpace(h.Row.Name), h.Desc, h.location())
+		}
+		return tw.Flush()
+	}
+
+	documents := filepath.Join(docsetPath, "Contents/Resources/Documents")
+	for i, h := range hits {
+		if i > 0 {
+			_, _ = io.WriteString(out, "\n")
+		}
+		_, _ = fmt.Fprintf(out, "%s %s", h.Row.Type, strings.TrimSpace(h.Row.Name))
+		if h.Desc != "" {
+			_, _ = fmt.Fprintf(out, " (%s)", h.Desc)
+		}
+		_, _ = fmt.Fprintf(out, "\n%s\n", h.location())
+		text, err := describeEntry(documents, h.DocPath, h.Target)
+		if err != nil {
+			return err
+		}
+		if text != "" {
+			_, _ = fmt.Fprintf(out, "\n%s\n", indent(text, "    "))
+		}
+	}
+	return nil
+}
+
+func (h *lookupHit) location() string {
+	if h.Target == "" {
+		return h.DocPath
+	}
+	return h.DocPath + "#" + h.Target
+}
+
+// searchRows returns the rows matching query, best matches first. Only rows of types are
+// returned, unless types is empty.
+func searchRows(rows []SearchIndex, query string, types []string) []lookupHit {
+	query = strings.ToLower(query)
+	var hits []lookupHit
+	for _, row := range rows {
+		if len(types) > 0 && !slices.ContainsFunc(types, func(t string) bool { return strings.EqualFold(t, row.Type) }) {
+			continue
+		}
+		h := lookupHit{Row: row, DocPath: row.Path}
+		h.Name, _, _ = strings.Cut(row.Name, "(")
+		h.Name = strings.TrimSpace(h.Name)
+		if h.Score = matchScore(strings.ToLower(h.Name), query); h.Score == 0 {
+			continue
+		}
+		if m := searchIndexPath.FindStringSubmatch(row.Path); m != nil {
+			h.Desc, h.DocPath, h.Target = m[3], m[4], m[5]
+		}
+		hits = append(hits, h)
+	}
+
+	slices.SortFunc(hits, func(a, b lookupHit) int {
+		return cmp.Or(
+			-cmp.Compare(a.Score, b.Score),
+			cmp.Compare(len(a.Name), len(b.Name)),
+			cmp.Compare(a.Name, b.Name),
+			cmp.Compare(a.Row.Type, b.Row.Type),
+			cmp.Compare(a.Desc, b.Desc),
+		)
+	})
+	return hits
+}
+
+// matchScore scores how well name matches query, both in lower case, or returns 0 if it
+// does not match.
+func matchScore(name, query string) int {
+	switch {
+	case name == query:
+		return 4
+	case strings.HasPrefix(name, query):
+		return 3
+	case strings.Contains(name, query):
+		return 2
+	case isSubsequence(name, query):
+		return 1
+	}
+	return 0
+}
+
+// isSubsequence reports whether the characters of query appear in s in order, e.g.
+// "qfree" matches queue_free.
+func isSubsequence(s, query string) bool {
+	for _, r := range query {
+		i := strings.IndexRune(s, r)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(string(r)):]
+	}
+	return true
+}
+
+// describeEntry returns the description of an entry as plain text: the description of a
+// member, or of the class for the page of a class.
+func describeEntry(documents, docPath, target string) (string, error) {
+	f, err := os.Open(filepath.Join(documents, docPath))
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	root, err := html.Parse(f)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse %s", docPath)
+	}
+	doc := goquery.NewDocumentFromNode(root)
+
+	if target == "" {
+		return plainText(classDescription(doc)), nil
+	}
+	anchor := doc.Find(`a.dashAnchor[name="` + target + `"]`).First()
+	return plainText(memberDescription(anchor)), nil
+}
+
+func indent(s, prefix string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
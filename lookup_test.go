@@ -0,0 +1,59 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSearchRows(t *testing.T) {
+	rows := []SearchIndex{
+		{Name: "far_eye", Type: "Property"},
+		{Name: "queue_free()", Type: "Method"},
+		{Name: "FreeType", Type: "Class"},
+		{Name: "free_rid(rid: RID)", Type: "Method"},
+		{Name: "free()", Type: "Method"},
+		{Name: "fire", Type: "Method"},
+	}
+	tests := []struct {
+		name  string
+		query string
+		types []string
+		want  []string
+	}{
+		// exact, prefix, substring and subsequence matches, the shortest name first
+		{"ranking", "free", nil, []string{"free", "FreeType", "free_rid", "queue_free", "far_eye"}},
+		{"upper case query", "FREE_R", nil, []string{"free_rid"}},
+		{"type", "free", []string{"class"}, []string{"FreeType"}},
+		{"types", "free", []string{"Class", "Property"}, []string{"FreeType", "far_eye"}},
+		{"no match", "xyz", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, h := range searchRows(rows, tt.query, tt.types) {
+				got = append(got, h.Name)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("searchRows(%q, %v) = %v, want %v", tt.query, tt.types, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchScore(t *testing.T) {
+	tests := []struct {
+		name, query string
+		want        int
+	}{
+		{"queue_free", "queue_free", 4},
+		{"queue_free", "queue", 3},
+		{"queue_free", "free", 2},
+		{"queue_free", "qfree", 1},
+		{"queue_free", "freeq", 0},
+	}
+	for _, tt := range tests {
+		if got := matchScore(tt.name, tt.query); got != tt.want {
+			t.Errorf("matchScore(%q, %q) = %d, want %d", tt.name, tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// manSection is the section of the manual with the class pages, the library section.
+const manSection = "3"
+
+// manSections are the sections of a man page, in the order of the class page, with the
+// entry types of the members they list.
+var manSections = []struct {
+	Title string
+	Type  string
+}{
+	{"PROPERTIES", "Property"},
+	{"CONSTRUCTORS", "Constructor"},
+	{"METHODS", "Method"},
+	{"OPERATORS", "Operator"},
+	{"SIGNALS", "Signal"},
+	{"ENUMERATIONS", "Enum"},
+	{"CONSTANTS", "Constant"},
+}
+
+// manPages maps the classes with a man page to their brief description, for the whatis
+// database.
+var manPages = make(map[string]string)
+
+// manPageName returns the name of the man page of a class, e.g. godot-Node.
+func manPageName(class string) string {
+	return "godot-" + class
+}
+
+// writeManPage writes the man page of the class with the title h1, with the description
+// of the class and of its members, as found from the dashtoc anchors of rows.
+func writeManPage(class, brief string, h1 *goquery.Selection, doc *goquery.Document, rows []SearchIndex) error {
+	name := manPageName(class)
+
+	w := &roffWriter{}
+	source := strings.TrimSpace("Godot " + version)
+	w.request(fmt.Sprintf(`.TH "%s" %s "" "%s" "Godot API Reference"`, roffEscape(strings.ToUpper(name)), manSection, roffEscape(source)))
+	w.request(".SH NAME")
+	w.text(roffEscape(name) + ` \- ` + roffEscape(brief))
+
+	w.request(".SH DESCRIPTION")
+	h1.NextAllFiltered("p").Each(func(_ int, p *goquery.Selection) {
+		if isLabelled(p) {
+			return // notes such as "Inherits:"; the parent is listed in SEE ALSO
+		}
+		w.request(".PP")
+		w.write(p.Nodes...)
+	})
+	for _, n := range classDescription(doc).Nodes {
+		w.request(".PP")
+		w.write(n)
+	}
+
+	for _, sec := range manSections {
+		members := lo.Filter(rows, func(r SearchIndex, _ int) bool { return r.Type == sec.Type })
+		if len(members) == 0 {
+			continue
+		}
+		w.request(".SH " + sec.Title)
+		for _, row := range members {
+			sel := memberDescription(doc.Find(`a.dashAnchor[name="` + row.Member.Target + `"]`).First())
+			if sel.Length() == 0 {
+				continue
+			}
+			w.request(".TP")
+			w.text(`\fB` + roffEscape(plainText(sel.First())) + `\fR`)
+			for i, n := range sel.Slice(1, sel.Length()).Nodes {
+				if i > 0 {
+					w.request(".sp")
+				}
+				w.write(n)
+			}
+		}
+	}
+
+	if parent := classParent(h1); parent != "" {
+		w.request(".SH SEE ALSO")
+		w.text(fmt.Sprintf(`\fB%s\fR(%s)`, roffEscape(manPageName(parent)), manSection))
+	}
+
+	dest := filepath.Join(targetPath, "man"+manSection, name+"."+manSection)
+	return errors.Wrapf(writeFile(dest, []byte(w.String())), "failed to write man page of %s", class)
+}
+
+// writeWhatis writes the whatis database of the man pages, used by apropos and man -k.
+func writeWhatis() error {
+	slog.Info("Write whatis database.", "pages", len(manPages))
+
+	classes := lo.Keys(manPages)
+	slices.SortFunc(classes, func(a, b string) int {
+		return cmp.Compare(strings.ToLower(a), strings.ToLower(b))
+	})
+	var sb strings.Builder
+	for _, class := range classes {
+		_, _ = fmt.Fprintf(&sb, "%-24s - %s\n", manPageName(class)+"("+manSection+")", manPages[class])
+	}
+	return errors.Wrap(writeFile(filepath.Join(targetPath, "whatis"), []byte(sb.String())), "failed to write whatis")
+}
+
+// roffWriter converts HTML to roff. Requests are marked while writing, so that text lines
+// starting with a control character can be escaped when the page is assembled.
+type roffWriter struct {
+	sb strings.Builder
+}
+
+// roffRequest marks the lines of roff requests.
+const roffRequest = "\x00"
+
+func (w *roffWriter) request(req string) {
+	w.sb.WriteString("\n" + roffRequest + req + "\n")
+}
+
+// text writes a line of roff text, which must already be escaped.
+func (w *roffWriter) text(s string) {
+	w.sb.WriteString("\n" + s + "\n")
+}
+
+func (w *roffWriter) write(nodes ...*html.Node) {
+	for _, n := range nodes {
+		w.node(n)
+	}
+}
+
+func (w *roffWriter) node(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		w.sb.WriteString(roffEscape(spaces.ReplaceAllString(n.Data, " ")))
+		return
+	case html.ElementNode:
+		if skipText(n) {
+			return
+		}
+	default:
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Pre:
+		w.request(".nf")
+		w.sb.WriteString(roffEscape(strings.Trim(nodeText(n), "\n")))
+		w.request(".fi")
+	case atom.Strong, atom.B:
+		w.font(n, `\fB`)
+	case atom.Em, atom.I:
+		w.font(n, `\fI`)
+	case atom.Li:
+		w.request(`.IP \(bu 2`)
+		w.children(n)
+	case atom.Br:
+		w.request(".br")
+	default:
+		block := blockElements[n.DataAtom]
+		if block {
+			w.sb.WriteString("\n")
+		}
+		w.children(n)
+		if block {
+			w.sb.WriteString("\n")
+		}
+	}
+}
+
+func (w *roffWriter) children(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		w.node(c)
+	}
+}
+
+func (w *roffWriter) font(n *html.Node, font string) {
+	w.sb.WriteString(font)
+	w.children(n)
+	w.sb.WriteString(`\fR`)
+}
+
+// String returns the roff document. Blank lines are removed outside of preformatted text,
+// as roff prints them, and text lines starting with a control character are escaped.
+func (w *roffWriter) String() string {
+	var out []string
+	var nofill bool
+	for _, line := range strings.Split(w.sb.String(), "\n") {
+		if req, ok := strings.CutPrefix(line, roffRequest); ok {
+			// consecutive spacing requests are collapsed into the first
+			if len(out) > 0 && isRoffSpacing(req) && isRoffSpacing(out[len(out)-1]) {
+				continue
+			}
+			nofill = req == ".nf" || nofill && req != ".fi"
+			out = append(out, req)
+			continue
+		}
+		if nofill {
+			line = strings.TrimRight(line, " \t")
+		} else if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		if line != "" && (line[0] == '.' || line[0] == '\'') {
+			line = `\&` + line
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n") + "\n"
+}
+
+func isRoffSpacing(req string) bool {
+	return req == ".sp" || req == ".PP"
+}
+
+// roffEscape escapes the backslashes of s, and the hyphens, which roff may otherwise
+// render as typographic dashes.
+func roffEscape(s string) string {
+	return strings.NewReplacer(`\`, `\e`, "-", `\-`).Replace(s)
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	css "github.com/andybalholm/cascadia"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// selMain is the content of a Sphinx page, without the navigation and footer.
+var selMain = css.MustCompile(`div[role="main"]`)
+
+// writeMarkdownPage writes the content of a page to dest as Markdown, replacing the .html
+// extension of dest with .md.
+func writeMarkdownPage(dest string, doc *goquery.Document) error {
+	content := doc.FindMatcher(selMain).First()
+	if content.Length() == 0 {
+		content = doc.Find("body")
+	}
+	w := &markdownWriter{localLinks: true}
+	for _, n := range content.Nodes {
+		w.children(n)
+	}
+	dest = strings.TrimSuffix(dest, ".html") + ".md"
+	return writeFile(dest, []byte(squeezeLines(w.sb.String())+"\n"))
+}
+
+// markdownWriter converts HTML to Markdown. Links are resolved against base, if set, and
+// relative links to .html pages are rewritten to the .md pages with localLinks.
+type markdownWriter struct {
+	sb         strings.Builder
+	base       *url.URL
+	localLinks bool
+}
+
+// markdownText returns the nodes of s as Markdown, with links resolved against base.
+func markdownText(s *goquery.Selection, base string) string {
+	w := &markdownWriter{}
+	if base != "" {
+		w.base, _ = url.Parse(base)
+	}
+	for _, n := range s.Nodes {
+		w.write(n)
+	}
+	return squeezeLines(w.sb.String())
+}
+
+func (w *markdownWriter) write(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		text := spaces.ReplaceAllString(n.Data, " ")
+		if endsWithSpace(&w.sb) {
+			text = strings.TrimPrefix(text, " ")
+		}
+		w.sb.WriteString(text)
+		return
+	case html.ElementNode:
+		if skipText(n) {
+			return
+		}
+	case html.DocumentNode:
+	default:
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Pre:
+		w.sb.WriteString("\n\n```" + codeLanguage(n) + "\n")
+		w.sb.WriteString(strings.Trim(nodeText(n), "\n"))
+		w.sb.WriteString("\n```\n\n")
+	case atom.Code, atom.Kbd:
+		w.sb.WriteString("`" + nodeText(n) + "`")
+	case atom.Strong, atom.B:
+		w.wrap(n, "**")
+	case atom.Em, atom.I:
+		w.wrap(n, "*")
+	case atom.A:
+		href := attr(n, "href")
+		if href == "" {
+			w.children(n)
+			return
+		}
+		w.sb.WriteString("[")
+		w.children(n)
+		w.sb.WriteString("](" + w.resolve(href) + ")")
+	case atom.Img:
+		w.sb.WriteString("![" + attr(n, "alt") + "](" + w.resolve(attr(n, "src")) + ")")
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level := int(n.Data[1] - '0')
+		w.sb.WriteString("\n\n" + strings.Repeat("#", level) + " ")
+		w.children(n)
+		w.sb.WriteString("\n\n")
+	case atom.Li:
+		// the paragraphs of an item are indented to stay in the item
+		w.sb.WriteString("\n- " + strings.ReplaceAll(w.inner(n), "\n", "\n  ") + "\n")
+	case atom.Br:
+		w.sb.WriteString("\n")
+	case atom.Tr:
+		w.sb.WriteString("\n|")
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.DataAtom == atom.Td || c.DataAtom == atom.Th {
+				// cells are on a single line
+				cell := spaces.ReplaceAllString(w.inner(c), " ")
+				w.sb.WriteString(" " + strings.ReplaceAll(cell, "|", `\|`) + " |")
+			}
+		}
+		if isFirstRow(n) {
+			w.sb.WriteString("\n|" + strings.Repeat(" --- |", cellCount(n)))
+		}
+	case atom.Table:
+		w.sb.WriteString("\n\n")
+		w.children(n)
+		w.sb.WriteString("\n\n")
+	default:
+		block := blockElements[n.DataAtom]
+		if block {
+			w.sb.WriteString("\n\n")
+		}
+		w.children(n)
+		if block {
+			w.sb.WriteString("\n\n")
+		}
+	}
+}
+
+func (w *markdownWriter) children(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		w.write(c)
+	}
+}
+
+// inner returns the children of n as Markdown.
+func (w *markdownWriter) inner(n *html.Node) string {
+	inner := &markdownWriter{base: w.base, localLinks: w.localLinks}
+	inner.children(n)
+	return squeezeLines(inner.sb.String())
+}
+
+func (w *markdownWriter) wrap(n *html.Node, marker string) {
+	w.sb.WriteString(marker)
+	w.children(n)
+	w.sb.WriteString(marker)
+}
+
+func (w *markdownWriter) resolve(href string) string {
+	if w.base == nil && !w.localLinks {
+		return href
+	}
+	u, err := url.Parse(href)
+	if err != nil {
+		return href
+	}
+	if w.localLinks && !u.IsAbs() && u.Host == "" && path.Ext(u.Path) == ".html" {
+		u.Path = strings.TrimSuffix(u.Path, ".html") + ".md"
+	}
+	if w.base == nil {
+		return u.String()
+	}
+	return w.base.ResolveReference(u).String()
+}
+
+// nodeText returns the text of n, keeping its whitespace.
+func nodeText(n *html.Node) string {
+	var sb strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return sb.String()
+}
+
+// codeLanguage returns the language of a code block from the highlight-<lang> class of
+// Sphinx, which is set on an ancestor of the <pre>.
+func codeLanguage(n *html.Node) string {
+	for ; n != nil; n = n.Parent {
+		for _, class := range strings.Fields(attr(n, "class")) {
+			if lang, ok := strings.CutPrefix(class, "highlight-"); ok && lang != "default" {
+				return lang
+			}
+		}
+	}
+	return ""
+}
+
+func isFirstRow(tr *html.Node) bool {
+	for p := tr.PrevSibling; p != nil; p = p.PrevSibling {
+		if p.DataAtom == atom.Tr {
+			return false
+		}
+	}
+	// rows of a <tbody> following a <thead> are not the first
+	return tr.Parent == nil || tr.Parent.DataAtom != atom.Tbody || tr.Parent.PrevSibling == nil ||
+		!hasPrevElement(tr.Parent, atom.Thead)
+}
+
+func hasPrevElement(n *html.Node, a atom.Atom) bool {
+	for p := n.PrevSibling; p != nil; p = p.PrevSibling {
+		if p.DataAtom == a {
+			return true
+		}
+	}
+	return false
+}
+
+func cellCount(tr *html.Node) int {
+	var count int
+	for c := tr.FirstChild; c != nil; c = c.NextSibling {
+		if c.DataAtom == atom.Td || c.DataAtom == atom.Th {
+			count++
+		}
+	}
+	return count
+}
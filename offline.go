@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+	css "github.com/andybalholm/cascadia"
+	"github.com/samber/lo"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// offlineScripts are the substrings of the source, or the code, of the analytics and search
+// scripts removed from the pages with --offline.
+var offlineScripts = []string{
+	"googletagmanager.com", "google-analytics.com", "gtag(", "dataLayer", "plausible.io",
+	"readthedocs", "searchtools.js", "language_data.js", "docsearch", "algolia",
+}
+
+var (
+	selScripts      = css.MustCompile("script")
+	selSearchForms  = css.MustCompile(`div[role="search"], readthedocs-flyout`)
+	selRemoteStyles = css.MustCompile(`link[rel="stylesheet"], link[rel="preconnect"], link[rel="dns-prefetch"]`)
+	selLinks        = css.MustCompile("a[href]")
+)
+
+// offlineClass is added to the external links of a page, which are marked with an arrow.
+const offlineClass = "offline-external"
+
+const offlineStyle = `a.` + offlineClass + `::after { content: "\2197"; font-size: 80%; margin-left: 0.15em; }`
+
+// offlineReport counts the changes made by the offline pass, for the summary written once
+// the docset is built.
+type offlineReport struct {
+	mu           sync.Mutex
+	scripts      int
+	searchForms  int
+	remoteStyles int
+	editLinks    int
+	localLinks   int
+	external     map[string]int // external counts the external links by host
+}
+
+var offlineRewrites = newOfflineReport()
+
+func newOfflineReport() *offlineReport {
+	return &offlineReport{external: make(map[string]int)}
+}
+
+// rewriteOffline prepares the page at docPath for offline use. The analytics and search
+// scripts, the search form and remote stylesheets are removed, links to the online
+// documentation of the same version are rewritten to the local pages and the remaining
+// external links are marked.
+func rewriteOffline(docPath string, root *html.Node, doc *goquery.Document) {
+	var page offlineReport
+	page.external = make(map[string]int)
+
+	doc.FindMatcher(selScripts).Each(func(_ int, s *goquery.Selection) {
+		code := s.AttrOr("src", "") + s.Text()
+		if slices.ContainsFunc(offlineScripts, func(v string) bool { return strings.Contains(code, v) }) {
+			s.Remove()
+			page.scripts++
+		}
+	})
+	page.searchForms = doc.FindMatcher(selSearchForms).Remove().Length()
+	doc.FindMatcher(selRemoteStyles).Each(func(_ int, s *goquery.Selection) {
+		if u, err := url.Parse(s.AttrOr("href", "")); err == nil && u.Host != "" {
+			s.Remove()
+			page.remoteStyles++
+		}
+	})
+
+	docsURL, _ := url.Parse(bundle{Lang: lang, Version: version}.DocsURL())
+	doc.FindMatcher(selLinks).Each(func(_ int, s *goquery.Selection) {
+		u, err := url.Parse(s.AttrOr("href", ""))
+		if err != nil || u.Host == "" {
+			return
+		}
+		if u.Host == "github.com" && strings.Contains(u.Path, "/edit/") {
+			s.Remove()
+			page.editLinks++
+			return
+		}
+		if href, ok := localHref(docPath, docsURL, u); ok {
+			s.SetAttr("href", href)
+			page.localLinks++
+			return
+		}
+		if u.Scheme == "http" || u.Scheme == "https" {
+			s.AddClass(offlineClass)
+			page.external[u.Host]++
+		}
+	})
+
+	if len(page.external) > 0 {
+		if head := selHead.MatchFirst(root); head != nil {
+			style := &html.Node{Type: html.ElementNode, DataAtom: atom.Style, Data: atom.Style.String()}
+			style.AppendChild(&html.Node{Type: html.TextNode, Data: offlineStyle})
+			head.AppendChild(style)
+		}
+	}
+
+	offlineRewrites.add(&page)
+}
+
+// localHref returns the link from the page at docPath to the local copy of the page of the
+// online documentation at u, if u is a page of the documentation being built.
+func localHref(docPath string, docsURL, u *url.URL) (string, bool) {
+	if docsPath == "" || u.Host != docsURL.Host {
+		return "", false
+	}
+	target, ok := strings.CutPrefix(u.Path, docsURL.Path)
+	if !ok {
+		return "", false
+	}
+	if target == "" || strings.HasSuffix(target, "/") {
+		target += "index.html"
+	}
+	if !isFile(filepath.Join(docsPath, filepath.FromSlash(target))) {
+		return "", false
+	}
+	href, err := filepath.Rel(filepath.Dir(filepath.FromSlash(docPath)), filepath.FromSlash(target))
+	if err != nil {
+		return "", false
+	}
+	href = filepath.ToSlash(href)
+	if u.Fragment != "" {
+		href += "#" + u.Fragment
+	}
+	return href, true
+}
+
+func (r *offlineReport) add(page *offlineReport) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.scripts += page.scripts
+	r.searchForms += page.searchForms
+	r.remoteStyles += page.remoteStyles
+	r.editLinks += page.editLinks
+	r.localLinks += page.localLinks
+	for host, n := range page.external {
+		r.external[host] += n
+	}
+}
+
+// log writes the summary of the offline pass.
+func (r *offlineReport) log() {
+	hosts := lo.Keys(r.external)
+	slices.Sort(hosts)
+	external := lo.Map(hosts, func(host string, _ int) string {
+		return fmt.Sprintf("%s=%d", host, r.external[host])
+	})
+	slog.Info("Rewrote pages for offline use.",
+		"scripts_removed", r.scripts,
+		"search_forms_removed", r.searchForms,
+		"remote_stylesheets_removed", r.remoteStyles,
+		"edit_links_removed", r.editLinks,
+		"links_made_local", r.localLinks,
+		"external_links_marked", lo.Sum(lo.Values(r.external)),
+	)
+	if len(external) > 0 {
+		slog.Info("External links.", "hosts", strings.Join(external, ", "))
+	}
+}
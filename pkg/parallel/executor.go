@@ -11,6 +11,7 @@ import (
 // New instances are created using NewExecutor().
 type Executor struct {
 	numGoroutines    int
+	strategyType     StrategyType
 	parallelStrategy Strategy
 }
 
@@ -18,6 +19,7 @@ type Executor struct {
 func NewExecutor() *Executor {
 	e := new(Executor)
 	e.numGoroutines = DefaultNumGoroutines()
+	e.strategyType = StrategyUseDefaults
 	return e
 }
 
@@ -46,14 +48,8 @@ func (e *Executor) WithCPUProportion(p float64) *Executor {
 // If either StrategyUseDefaults or an unrecognized value is specified, the
 // defaults will be used for both For() and ForWithContext().
 func (e *Executor) WithStrategy(strategyType StrategyType) *Executor {
-	switch strategyType {
-	case StrategyPreassignIndices:
-		e.parallelStrategy = newContiguousBlocksStrategy()
-	case StrategyFetchNextIndex:
-		e.parallelStrategy = newAtomicCounterStrategy()
-	default:
-		e.parallelStrategy = nil
-	}
+	e.strategyType = strategyType
+	e.parallelStrategy = nil
 	return e
 }
 
@@ -61,10 +57,32 @@ func (e *Executor) WithStrategy(strategyType StrategyType) *Executor {
 // Defining custom strategies is an advanced feature. Most users should instead specify one of the
 // strategies built into this package using WithStrategy().
 func (e *Executor) WithCustomStrategy(customStrategy Strategy) *Executor {
+	e.strategyType = StrategyUseDefaults
 	e.parallelStrategy = customStrategy
 	return e
 }
 
+// loopStrategy returns the strategy for a single loop. Built-in strategies keep shared state
+// for the duration of a loop, so a new instance is created for every call, which allows an
+// Executor to be reused.
+func (e *Executor) loopStrategy(defaultStrategy func() Strategy) Strategy {
+	if e.parallelStrategy != nil {
+		return e.parallelStrategy
+	}
+	switch e.strategyType {
+	case StrategyPreassignIndices:
+		return newContiguousBlocksStrategy()
+	case StrategyFetchNextIndex:
+		return newAtomicCounterStrategy()
+	case StrategyWorkStealing:
+		return newWorkStealingStrategy()
+	case StrategyLargestFirst:
+		return newLargestFirstStrategy()
+	default:
+		return defaultStrategy()
+	}
+}
+
 // For executes N iterations of a function body, where the iterations are parallelized among a
 // number of goroutines and returns the first observed error from loopBody.
 //
@@ -87,11 +105,24 @@ func (e *Executor) WithCustomStrategy(customStrategy Strategy) *Executor {
 // If loopBody returns an error, it will no longer be called.
 func (e *Executor) For(N int, loopBody func(i, grID int) error) error {
 	// use default contiguous blocks strategy if strategy has not been specified on executor
-	strategy := e.parallelStrategy
-	if strategy == nil {
-		strategy = newContiguousBlocksStrategy()
+	return e.forStrategy(e.loopStrategy(newContiguousBlocksStrategy), N, loopBody)
+}
+
+// ForWeighted is the same as For(), but includes the cost of each work item, such as the size of
+// an input file, returned by weight. The weights are passed to the strategy if it implements
+// WeightedStrategy and are ignored otherwise.
+//
+// By default, ForWeighted() uses the largest first strategy, so the most expensive iterations are
+// started first.
+func (e *Executor) ForWeighted(N int, weight func(i int) int64, loopBody func(i, grID int) error) error {
+	strategy := e.loopStrategy(newLargestFirstStrategy)
+	if ws, ok := strategy.(WeightedStrategy); ok {
+		strategy = ws.WithWeights(N, weight)
 	}
+	return e.forStrategy(strategy, N, loopBody)
+}
 
+func (e *Executor) forStrategy(strategy Strategy, N int, loopBody func(i, grID int) error) error {
 	var wg sync.WaitGroup
 	wg.Add(e.numGoroutines)
 
@@ -125,15 +156,34 @@ func (e *Executor) For(N int, loopBody func(i, grID int) error) error {
 // ForWithContext is the same as For(), but includes a context argument to enable timeout,
 // cancellation, and other context capabilities.
 //
-// If any loopBody returns an error, all cooperating goroutines will exit on their next iteration.
+// By default, ForWithContext() uses the atomic counter strategy.
+//
+// If any loopBody returns an error, all cooperating goroutines will exit on their next iteration
+// and the first observed error is returned. Otherwise, ctx.Err() is returned, which is nil if the
+// loop completed without ctx ending.
 func (e *Executor) ForWithContext(ctx context.Context, N int,
 	loopBody func(ctx context.Context, i, grID int) error) error {
 
 	// use default atomic counter strategy if strategy has not been specified on executor
-	strategy := e.parallelStrategy
-	if strategy == nil {
-		strategy = newContiguousBlocksStrategy()
+	return e.forStrategyWithContext(ctx, e.loopStrategy(newAtomicCounterStrategy), N, loopBody)
+}
+
+// ForWeightedWithContext is the same as ForWithContext(), but includes the cost of each work
+// item, returned by weight, as described for ForWeighted().
+//
+// By default, ForWeightedWithContext() uses the largest first strategy.
+func (e *Executor) ForWeightedWithContext(ctx context.Context, N int, weight func(i int) int64,
+	loopBody func(ctx context.Context, i, grID int) error) error {
+
+	strategy := e.loopStrategy(newLargestFirstStrategy)
+	if ws, ok := strategy.(WeightedStrategy); ok {
+		strategy = ws.WithWeights(N, weight)
 	}
+	return e.forStrategyWithContext(ctx, strategy, N, loopBody)
+}
+
+func (e *Executor) forStrategyWithContext(ctx context.Context, strategy Strategy, N int,
+	loopBody func(ctx context.Context, i, grID int) error) error {
 
 	var wg sync.WaitGroup
 	wg.Add(e.numGoroutines)
@@ -171,5 +221,10 @@ func (e *Executor) ForWithContext(ctx context.Context, N int,
 
 	wg.Wait()
 
-	return loopErr
+	if loopErr != nil {
+		return loopErr
+	}
+
+	// report cancellation of the parent context, as the loop may not have completed
+	return ctx.Err()
 }
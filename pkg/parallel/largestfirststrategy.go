@@ -0,0 +1,62 @@
+package parallel
+
+import (
+	"sort"
+	"sync/atomic"
+)
+
+type largestFirstStrategy struct {
+	// order is the sequence of work indices, sorted by decreasing weight.
+	// If nil, indices are handed out in ascending order.
+	order   []int
+	counter int64
+}
+
+func newLargestFirstStrategy() Strategy {
+	return &largestFirstStrategy{
+		counter: -1, // first receiver will increment atomically and receive 0
+	}
+}
+
+func (s *largestFirstStrategy) WithWeights(N int, weight func(i int) int64) Strategy {
+	weights := make([]int64, N)
+	order := make([]int, N)
+	for i := range order {
+		order[i] = i
+		weights[i] = weight(i)
+	}
+	// stable, so items of equal weight keep their relative order
+	sort.SliceStable(order, func(a, b int) bool {
+		return weights[order[a]] > weights[order[b]]
+	})
+
+	return &largestFirstStrategy{
+		order:   order,
+		counter: -1,
+	}
+}
+
+func (s *largestFirstStrategy) IndexGenerator(_, _, N int) IndexGenerator {
+	return &largestFirstIndexGenerator{
+		order:       s.order,
+		counterAddr: &s.counter,
+		doneIndex:   N,
+	}
+}
+
+type largestFirstIndexGenerator struct {
+	order       []int
+	counterAddr *int64
+	doneIndex   int
+}
+
+func (g *largestFirstIndexGenerator) Next() int {
+	next := int(atomic.AddInt64(g.counterAddr, 1))
+	if g.order == nil {
+		return next
+	}
+	if next >= len(g.order) {
+		return g.doneIndex
+	}
+	return g.order[next]
+}
@@ -28,13 +28,21 @@ func For(N int, loopBody func(i, grID int) error) error {
 	return NewExecutor().For(N, loopBody)
 }
 
+// ForWeighted is the same as For(), but includes the cost of each work item, returned by weight.
+// By default, ForWeighted() uses the largest first strategy, so the most expensive iterations are
+// started first.
+func ForWeighted(N int, weight func(i int) int64, loopBody func(i, grID int) error) error {
+	return NewExecutor().ForWeighted(N, weight, loopBody)
+}
+
 // ForWithContext is the same as For(), but includes a context argument to enable timeout,
 // cancellation, and other context capabilities.
 // By default, ForWithContext() uses the atomic counter strategy instead of contiguous index
-// blocks. The corresponding ctx.Err() is returned, and will be nil if the loop completed
-// successfully. The context ctx is propagated directly to loop iterations. This context is also
-// checked between loop iterations, so long-running loops will exit prior to completion if ctx is
-// ended, even if ctx is unused within the loop body.
+// blocks. The first error returned by loopBody is returned, otherwise the corresponding ctx.Err()
+// is returned, and will be nil if the loop completed successfully. The context ctx is
+// propagated directly to loop iterations. This context is also checked between loop
+// iterations, so long-running loops will exit prior to completion if ctx is ended, even if ctx
+// is unused within the loop body.
 //
 // On loops that do not require the use of context, For() is recommended as it is slightly faster.
 func ForWithContext(ctx context.Context, N int,
@@ -42,6 +50,14 @@ func ForWithContext(ctx context.Context, N int,
 	return NewExecutor().ForWithContext(ctx, N, loopBody)
 }
 
+// ForWeightedWithContext is the same as ForWithContext(), but includes the cost of each work item,
+// returned by weight. By default, ForWeightedWithContext() uses the largest first strategy, so the
+// most expensive iterations are started first.
+func ForWeightedWithContext(ctx context.Context, N int, weight func(i int) int64,
+	loopBody func(ctx context.Context, i, grID int) error) error {
+	return NewExecutor().ForWeightedWithContext(ctx, N, weight, loopBody)
+}
+
 // WithNumGoroutines returns a default executor, but using a specific number of goroutines.
 func WithNumGoroutines(n int) *Executor {
 	return NewExecutor().WithNumGoroutines(n)
@@ -0,0 +1,185 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+)
+
+type loopBody = func(ctx context.Context, i, grID int) error
+
+// loopFunc runs a loop of N iterations with one of the loop methods of an ExecutorRuntime.
+type loopFunc func(e ExecutorRuntime, ctx context.Context, N int, body loopBody) error
+
+var loopFuncs = []struct {
+	name        string
+	withContext bool
+	run         loopFunc
+}{
+	{"For", false, func(e ExecutorRuntime, _ context.Context, N int, body loopBody) error {
+		return e.For(N, func(i, grID int) error { return body(context.Background(), i, grID) })
+	}},
+	{"ForWeighted", false, func(e ExecutorRuntime, _ context.Context, N int, body loopBody) error {
+		return e.ForWeighted(N, skewedWeight, func(i, grID int) error {
+			return body(context.Background(), i, grID)
+		})
+	}},
+	{"ForWithContext", true, func(e ExecutorRuntime, ctx context.Context, N int, body loopBody) error {
+		return e.ForWithContext(ctx, N, body)
+	}},
+	{"ForWeightedWithContext", true, func(e ExecutorRuntime, ctx context.Context, N int, body loopBody) error {
+		return e.ForWeightedWithContext(ctx, N, skewedWeight, body)
+	}},
+}
+
+func skewedWeight(i int) int64 {
+	return int64(skewedCost(i))
+}
+
+// testExecutor is an executor under test, with the number of goroutines it runs loops on.
+type testExecutor struct {
+	name          string
+	numGoroutines int
+	e             ExecutorRuntime
+}
+
+func testExecutors() []testExecutor {
+	strategies := []struct {
+		name string
+		t    StrategyType
+	}{
+		{"defaults", StrategyUseDefaults},
+		{"preassign", StrategyPreassignIndices},
+		{"fetch-next", StrategyFetchNextIndex},
+		{"work-stealing", StrategyWorkStealing},
+		{"largest-first", StrategyLargestFirst},
+	}
+
+	executors := []testExecutor{{"serial", 1, SerialExecutor()}}
+	for _, s := range strategies {
+		for _, numGR := range []int{1, 3, 8} {
+			executors = append(executors, testExecutor{
+				name:          fmt.Sprintf("%s/goroutines=%d", s.name, numGR),
+				numGoroutines: numGR,
+				e:             NewExecutor().WithNumGoroutines(numGR).WithStrategy(s.t),
+			})
+		}
+	}
+	return executors
+}
+
+func TestLoopsRunEachIndexOnce(t *testing.T) {
+	for _, te := range testExecutors() {
+		for _, loop := range loopFuncs {
+			// no iterations, fewer iterations than goroutines, and many iterations
+			for _, N := range []int{0, 1, 2, 7, 1000, 100003} {
+				t.Run(fmt.Sprintf("%s/%s/N=%d", te.name, loop.name, N), func(t *testing.T) {
+					counts := make([]int32, N)
+					var badGR atomic.Int32
+					err := loop.run(te.e, context.Background(), N, func(_ context.Context, i, grID int) error {
+						if grID < 0 || grID >= te.numGoroutines {
+							badGR.Store(int32(grID) + 1)
+						}
+						atomic.AddInt32(&counts[i], 1)
+						return nil
+					})
+					if err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+					if v := badGR.Load(); v != 0 {
+						t.Errorf("goroutine ID %d out of range [0, %d)", v-1, te.numGoroutines)
+					}
+					for i, n := range counts {
+						if n != 1 {
+							t.Fatalf("index %d ran %d times, want 1", i, n)
+						}
+					}
+				})
+			}
+		}
+	}
+}
+
+var errLoop = errors.New("loop error")
+
+func TestLoopsStopOnError(t *testing.T) {
+	const N = 10000
+	for _, te := range testExecutors() {
+		for _, loop := range loopFuncs {
+			t.Run(te.name+"/"+loop.name, func(t *testing.T) {
+				var (
+					calls  atomic.Int32
+					failed = make([]atomic.Bool, te.numGoroutines)
+				)
+				err := loop.run(te.e, context.Background(), N, func(ctx context.Context, _, grID int) error {
+					if failed[grID].Load() {
+						t.Errorf("goroutine %d called after its loop body returned an error", grID)
+					}
+					if calls.Add(1) == 1 {
+						failed[grID].Store(true)
+						return errLoop
+					}
+					if loop.withContext {
+						// the other goroutines wait for the error to cancel the loop
+						<-ctx.Done()
+					}
+					return nil
+				})
+				if !errors.Is(err, errLoop) {
+					t.Errorf("got error %v, want %v", err, errLoop)
+				}
+				// without a context, only the goroutine returning the error stops
+				if n := int(calls.Load()); loop.withContext && n > te.numGoroutines {
+					t.Errorf("loop body called %d times, want at most %d", n, te.numGoroutines)
+				}
+			})
+		}
+	}
+}
+
+func TestLoopsStopOnCancel(t *testing.T) {
+	const N = 10000
+	for _, te := range testExecutors() {
+		for _, loop := range loopFuncs {
+			if !loop.withContext {
+				continue
+			}
+			t.Run(te.name+"/"+loop.name, func(t *testing.T) {
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				var calls atomic.Int32
+				err := loop.run(te.e, ctx, N, func(ctx context.Context, _, _ int) error {
+					if calls.Add(1) == 1 {
+						cancel()
+					}
+					<-ctx.Done()
+					return nil
+				})
+				if !errors.Is(err, context.Canceled) {
+					t.Errorf("got error %v, want %v", err, context.Canceled)
+				}
+				if n := int(calls.Load()); n > te.numGoroutines {
+					t.Errorf("loop body called %d times, want at most %d", n, te.numGoroutines)
+				}
+			})
+
+			t.Run(te.name+"/"+loop.name+"/canceled", func(t *testing.T) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				var calls atomic.Int32
+				err := loop.run(te.e, ctx, N, func(context.Context, int, int) error {
+					calls.Add(1)
+					return nil
+				})
+				if !errors.Is(err, context.Canceled) {
+					t.Errorf("got error %v, want %v", err, context.Canceled)
+				}
+				if n := calls.Load(); n != 0 {
+					t.Errorf("loop body called %d times on a canceled context, want 0", n)
+				}
+			})
+		}
+	}
+}
@@ -1,5 +1,9 @@
 package parallel
 
+import (
+	"context"
+)
+
 // ExecutorRuntime is an interface for executing a loop using a function.
 // The loopBody function is called for each iteration of the loop and may be called
 // on multiple goroutines.
@@ -8,26 +12,63 @@ package parallel
 type ExecutorRuntime interface {
 	// For executes a loop with N iterations, calling loopBody for each iteration.
 	For(N int, loopBody func(i, grID int) error) error
+
+	// ForWeighted executes a loop with N iterations, calling loopBody for each iteration.
+	// weight returns the cost of each iteration, which may be used to schedule iterations.
+	ForWeighted(N int, weight func(i int) int64, loopBody func(i, grID int) error) error
+
+	// ForWithContext executes a loop with N iterations, calling loopBody for each iteration,
+	// until ctx ends. The first error returned by loopBody is returned, otherwise ctx.Err().
+	ForWithContext(ctx context.Context, N int, loopBody func(ctx context.Context, i, grID int) error) error
+
+	// ForWeightedWithContext is the same as ForWithContext, but includes the cost of each
+	// iteration, as described for ForWeighted.
+	ForWeightedWithContext(ctx context.Context, N int, weight func(i int) int64,
+		loopBody func(ctx context.Context, i, grID int) error) error
 }
 
-// executorFunc is a function type that implements the ExecutorRuntime interface.
-type executorFunc func(N int, loopBody func(i, grID int) error) error
+var _ ExecutorRuntime = (*Executor)(nil)
+
+// serialExecutor implements the ExecutorRuntime interface on the current goroutine.
+type serialExecutor struct{}
+
+func (serialExecutor) For(N int, loopBody func(i, grID int) error) error {
+	for i := 0; i < N; i++ {
+		if err := loopBody(i, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-func (f executorFunc) For(N int, loopBody func(i, grID int) error) error {
-	return f(N, loopBody)
+// ForWeighted ignores the weights, as iterations are always executed in order.
+func (s serialExecutor) ForWeighted(N int, _ func(i int) int64, loopBody func(i, grID int) error) error {
+	return s.For(N, loopBody)
+}
+
+func (serialExecutor) ForWithContext(ctx context.Context, N int,
+	loopBody func(ctx context.Context, i, grID int) error) error {
+	for i := 0; i < N; i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := loopBody(ctx, i, 0); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+// ForWeightedWithContext ignores the weights, as iterations are always executed in order.
+func (s serialExecutor) ForWeightedWithContext(ctx context.Context, N int, _ func(i int) int64,
+	loopBody func(ctx context.Context, i, grID int) error) error {
+	return s.ForWithContext(ctx, N, loopBody)
 }
 
 // SerialExecutor returns an ExecutorRuntime that executes the loopBody function serially on the
-// current goroutine.
+// current goroutine, in ascending index order.
 //
 // The primary use case for a SerialExecutor is in testing.
 func SerialExecutor() ExecutorRuntime {
-	return executorFunc(func(N int, loopBody func(i, grID int) error) error {
-		for i := 0; i < N; i++ {
-			if err := loopBody(i, 0); err != nil {
-				return err
-			}
-		}
-		return nil
-	})
+	return serialExecutor{}
 }
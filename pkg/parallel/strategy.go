@@ -17,6 +17,18 @@ const (
 	// This strategy generally works best for API requests.
 	StrategyFetchNextIndex = StrategyType(iota)
 
+	// StrategyWorkStealing refers to a strategy where each goroutine starts with a contiguous
+	// block of work indices and takes them in chunks that shrink as the block drains. Goroutines
+	// that run out of work steal half of the remaining indices from another goroutine.
+	// This strategy works best when a few expensive iterations are mixed with many cheap ones.
+	StrategyWorkStealing = StrategyType(iota)
+
+	// StrategyLargestFirst refers to a strategy where goroutines pull the next available work
+	// index in order of decreasing cost, as given to ForWeighted(). Scheduling the longest
+	// iterations first reduces the time spent waiting on a single slow iteration at the end
+	// of the loop. Without weights, it behaves like StrategyFetchNextIndex.
+	StrategyLargestFirst = StrategyType(iota)
+
 	// StrategyUseDefaults may be specified on WithStrategy() calls to set the executor to use the
 	// default strategies for both For() and ForWithContext().
 	StrategyUseDefaults = StrategyType(-1)
@@ -34,6 +46,15 @@ type Strategy interface {
 	IndexGenerator(numGR, grID, N int) IndexGenerator
 }
 
+// WeightedStrategy defines an extension of Strategy for strategies that use the cost of each work
+// item to allocate indices. WithWeights is called once per loop by ForWeighted() and returns the
+// Strategy used for that loop, before any IndexGenerator is created. weight returns the cost of
+// the work item at index i, for 0 <= i < N.
+type WeightedStrategy interface {
+	Strategy
+	WithWeights(N int, weight func(i int) int64) Strategy
+}
+
 // IndexGenerator defines an interface for individual goroutines to retrieve their work indices.
 // IndexGenerator instances should only be created via a corresponding Strategy calling its
 // IndexGenerator() method.
@@ -0,0 +1,72 @@
+package parallel
+
+import (
+	"fmt"
+	"testing"
+)
+
+// skewedCost returns the cost of item i for a workload where a few expensive items sit among
+// many cheap ones, similar to a handful of very large pages in a set of small documents.
+func skewedCost(i int) int {
+	if i%97 == 0 {
+		return 20000
+	}
+	return 100
+}
+
+func uniformCost(int) int {
+	return 100
+}
+
+var sink int
+
+func spin(n int) int {
+	v := 0
+	for j := 0; j < n; j++ {
+		v += j ^ (v >> 3)
+	}
+	return v
+}
+
+func benchmarkStrategies(b *testing.B, N int, cost func(int) int) {
+	strategies := []struct {
+		name string
+		t    StrategyType
+	}{
+		{"preassign", StrategyPreassignIndices},
+		{"fetch-next", StrategyFetchNextIndex},
+		{"work-stealing", StrategyWorkStealing},
+		{"largest-first", StrategyLargestFirst},
+	}
+
+	for _, s := range strategies {
+		b.Run(s.name, func(b *testing.B) {
+			e := NewExecutor().WithStrategy(s.t)
+			results := make([]int, N)
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				body := func(i, _ int) error {
+					results[i] = spin(cost(i))
+					return nil
+				}
+				if s.t == StrategyLargestFirst {
+					_ = e.ForWeighted(N, func(i int) int64 { return int64(cost(i)) }, body)
+				} else {
+					_ = e.For(N, body)
+				}
+			}
+			sink = results[0]
+		})
+	}
+}
+
+func BenchmarkStrategies(b *testing.B) {
+	for _, N := range []int{100, 1000, 100000} {
+		b.Run(fmt.Sprintf("skewed/N=%d", N), func(b *testing.B) {
+			benchmarkStrategies(b, N, skewedCost)
+		})
+		b.Run(fmt.Sprintf("uniform/N=%d", N), func(b *testing.B) {
+			benchmarkStrategies(b, N, uniformCost)
+		})
+	}
+}
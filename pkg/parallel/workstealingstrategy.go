@@ -0,0 +1,124 @@
+package parallel
+
+import (
+	"sync"
+)
+
+// cacheLinePad is used to keep the per-goroutine ranges of the work-stealing strategy on
+// separate cache lines.
+const cacheLinePad = 64
+
+type workStealingStrategy struct {
+	once   sync.Once
+	ranges []stealRange
+}
+
+func newWorkStealingStrategy() Strategy {
+	return &workStealingStrategy{}
+}
+
+func (s *workStealingStrategy) IndexGenerator(numGR, grID, N int) IndexGenerator {
+	// every goroutine calls IndexGenerator with the same numGR and N, so the first caller
+	// assigns each goroutine its initial contiguous block
+	s.once.Do(func() {
+		s.ranges = make([]stealRange, numGR)
+		for id := range s.ranges {
+			s.ranges[id].next, s.ranges[id].stop = grIndexBlock(numGR, id, N)
+		}
+	})
+
+	return &workStealingIndexGenerator{
+		ranges:    s.ranges,
+		grID:      grID,
+		doneIndex: N,
+	}
+}
+
+// stealRange is the remaining work [next, stop) owned by a single goroutine. The owner takes
+// chunks from the front, while idle goroutines steal from the back.
+type stealRange struct {
+	mu         sync.Mutex
+	next, stop int
+	_          [cacheLinePad]byte
+}
+
+// take removes a chunk from the front of the range, which shrinks as the range drains.
+func (r *stealRange) take() (int, int, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	remaining := r.stop - r.next
+	if remaining <= 0 {
+		return 0, 0, false
+	}
+	chunk := maxInt(remaining/4, 1)
+	start := r.next
+	r.next += chunk
+	return start, r.next, true
+}
+
+// steal removes the back half of the range.
+func (r *stealRange) steal() (int, int, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	remaining := r.stop - r.next
+	if remaining <= 0 {
+		return 0, 0, false
+	}
+	stolen := (remaining + 1) / 2
+	stop := r.stop
+	r.stop -= stolen
+	return r.stop, stop, true
+}
+
+func (r *stealRange) set(next, stop int) {
+	r.mu.Lock()
+	r.next, r.stop = next, stop
+	r.mu.Unlock()
+}
+
+type workStealingIndexGenerator struct {
+	ranges    []stealRange
+	grID      int
+	doneIndex int
+
+	// current chunk being worked
+	nextIndex, stopIndex int
+}
+
+func (g *workStealingIndexGenerator) Next() int {
+	if g.nextIndex >= g.stopIndex && !g.refill() {
+		return g.doneIndex
+	}
+
+	thisIndex := g.nextIndex
+	g.nextIndex++
+
+	return thisIndex
+}
+
+// refill fetches the next chunk from the goroutine's own range or, once that is exhausted,
+// steals from the other goroutines.
+func (g *workStealingIndexGenerator) refill() bool {
+	own := &g.ranges[g.grID]
+	for {
+		if start, stop, ok := own.take(); ok {
+			g.nextIndex, g.stopIndex = start, stop
+			return true
+		}
+
+		stole := false
+		for i := 1; i < len(g.ranges); i++ {
+			victim := &g.ranges[(g.grID+i)%len(g.ranges)]
+			if start, stop, ok := victim.steal(); ok {
+				own.set(start, stop)
+				stole = true
+				break
+			}
+		}
+		if !stole {
+			return false
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"html/template"
+	"log/slog"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+	css "github.com/andybalholm/cascadia"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const referencesTitle = "Referenced by"
+
+// guideRef is a guide linking to a class page.
+type guideRef struct {
+	Title  string
+	Href   string // Href is the path of the guide, relative to the class page
+	Target string // Target is the dashtoc anchor of the guide on the class page
+}
+
+// guideReferences is the reverse index of the links from the guides to the class pages,
+// recorded with --referenced-by.
+type guideReferences struct {
+	mu     sync.Mutex
+	guides map[string]map[string]string // guides maps the path of a class page to the paths and titles of the guides linking to it
+}
+
+func newGuideReferences() *guideReferences {
+	return &guideReferences{guides: make(map[string]map[string]string)}
+}
+
+var references = newGuideReferences()
+
+// add records the class pages, or anchors of class pages, the guide at docPath links to.
+func (r *guideReferences) add(docPath, title string, doc *goquery.Document) {
+	classPages := make(map[string]struct{}, len(hierarchy.paths))
+	for _, p := range hierarchy.paths {
+		p, _, _ = strings.Cut(p, "#")
+		classPages[p] = struct{}{}
+	}
+	var pages []string
+	doc.FindMatcher(selLinks).Each(func(_ int, s *goquery.Selection) {
+		u, err := url.Parse(s.AttrOr("href", ""))
+		if err != nil || u.IsAbs() || u.Host != "" || u.Path == "" {
+			return
+		}
+		page := path.Join(path.Dir(docPath), u.Path)
+		if _, ok := classPages[page]; ok {
+			pages = append(pages, page)
+		}
+	})
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, page := range pages {
+		if r.guides[page] == nil {
+			r.guides[page] = make(map[string]string)
+		}
+		r.guides[page][docPath] = title
+	}
+}
+
+var (
+	selTutorialLinks = css.MustCompile("section#tutorials a[href]")
+	// selReferencesAfter are the sections the "Referenced by" section follows, in order
+	// of preference.
+	selReferencesAfter = []css.Selector{
+		css.MustCompile("section#tutorials"),
+		css.MustCompile("section#description"),
+	}
+)
+
+// writeReferences adds a "Referenced by" section to each class page linked from a guide,
+// listing the guides, except those already listed as tutorials of the class.
+func writeReferences(ctx context.Context) error {
+	pages := lo.Keys(references.guides)
+	slices.Sort(pages)
+	slog.Info("Write references.", "classes", len(pages))
+
+	return executor.ForWithContext(ctx, len(pages), func(_ context.Context, i, _ int) error {
+		return writeClassReferences(pages[i], references.guides[pages[i]])
+	})
+}
+
+func writeClassReferences(page string, guides map[string]string) error {
+	dest := filepath.Join(targetPath, filepath.FromSlash(page))
+	b, err := os.ReadFile(dest)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %s", page)
+	}
+	root, err := html.Parse(bytes.NewReader(b))
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse %s", page)
+	}
+	doc := goquery.NewDocumentFromNode(root)
+
+	dir := path.Dir(page)
+	tutorials := make(map[string]struct{})
+	doc.FindMatcher(selTutorialLinks).Each(func(_ int, s *goquery.Selection) {
+		if u, err := url.Parse(s.AttrOr("href", "")); err == nil {
+			tutorials[path.Join(dir, u.Path)] = struct{}{}
+		}
+	})
+
+	var refs []guideRef
+	for docPath, title := range guides {
+		if _, ok := tutorials[docPath]; ok || docPath == page {
+			continue
+		}
+		href, err := filepath.Rel(dir, docPath)
+		if err != nil {
+			continue
+		}
+		refs = append(refs, guideRef{Title: title, Href: filepath.ToSlash(href), Target: sectionTarget(title, "Guide", false)})
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+	slices.SortFunc(refs, func(a, b guideRef) int { return strings.Compare(a.Title, b.Title) })
+
+	section, err := referencesSection(refs)
+	if err != nil {
+		return err
+	}
+	var after *goquery.Selection
+	for _, sel := range selReferencesAfter {
+		if after = doc.FindMatcher(sel).First(); after.Length() > 0 {
+			break
+		}
+	}
+	if after.Length() > 0 {
+		n := after.Get(0)
+		for _, c := range section {
+			n.Parent.InsertBefore(c, n.NextSibling)
+			n = c
+		}
+	} else if body := doc.Find("body").Get(0); body != nil {
+		for _, c := range section {
+			body.AppendChild(c)
+		}
+	}
+
+	if head := selHead.MatchFirst(root); head != nil {
+		link, _, _ := newSectionHeaderLink(referencesTitle, "Guide")
+		head.AppendChild(link)
+		for _, ref := range refs {
+			link, _, _ := newSectionItemLink(ref.Title, "Guide")
+			head.AppendChild(link)
+		}
+	}
+	return renderHTML(dest, root)
+}
+
+// referencesSection returns the nodes of the "Referenced by" section listing refs.
+func referencesSection(refs []guideRef) ([]*html.Node, error) {
+	var buf bytes.Buffer
+	err := referencesTemplate.Execute(&buf, map[string]any{
+		"Title":  referencesTitle,
+		"Target": sectionTarget(referencesTitle, "Guide", true),
+		"Refs":   refs,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to render references")
+	}
+	return html.ParseFragment(&buf, &html.Node{Type: html.ElementNode, DataAtom: atom.Body, Data: atom.Body.String()})
+}
+
+var referencesTemplate = template.Must(template.New("references").Parse(`
+<section class="classref-introduction-group" id="referenced-by"><a class="dashAnchor" name="{{.Target}}"></a><h2>{{.Title}}<a class="headerlink" href="#referenced-by" title="Link to this heading">¶</a></h2><ul class="simple">
+{{range .Refs}}<li><p><a class="dashAnchor" name="{{.Target}}"></a><a class="reference internal" href="{{.Href}}"><span class="doc">{{.Title}}</span></a></p></li>
+{{end}}</ul>
+</section>`))
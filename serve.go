@@ -0,0 +1,302 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/html"
+)
+
+var (
+	serveCmd = &cobra.Command{
+		Use:   "serve",
+		Short: "Serve a docset over HTTP, with search and the table of contents of each page",
+		Long: `Serve the documentation of a docset over HTTP for browsers without Dash. The search page at
+/search and the JSON API at /api/search use the search index of the docset, and each page has
+a table of contents built from its dashtoc anchors, also available from /api/toc?path=PAGE.`,
+		Args:         cobra.NoArgs,
+		RunE:         serve,
+		SilenceUsage: true,
+	}
+	// arguments
+	serveAddr string
+	serveTOC  bool
+)
+
+func init() {
+	serveCmd.Flags().StringVar(&docsetPath, "docset-path", "", "The base path to the Godot.docset")
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "localhost:8080", "The address to listen on")
+	serveCmd.Flags().BoolVar(&serveTOC, "toc", true, "Add the table of contents to each page")
+	_ = cobra.MarkFlagRequired(serveCmd.Flags(), "docset-path")
+	cmd.AddCommand(serveCmd)
+}
+
+func serve(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+	srv, err := newDocsetServer(docsetPath, serveTOC)
+	if err != nil {
+		return err
+	}
+
+	ln, err := net.Listen("tcp", serveAddr)
+	if err != nil {
+		return err
+	}
+	httpSrv := &http.Server{Handler: srv, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = httpSrv.Shutdown(shutdownCtx)
+	}()
+
+	slog.Info("Serving docset.", "url", "http://"+ln.Addr().String()+"/", "search", "http://"+ln.Addr().String()+"/search")
+	if err = httpSrv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// docsetServer serves the documentation of a docset, its search index and the table of
+// contents of its pages.
+type docsetServer struct {
+	documents string
+	rows      []SearchIndex
+	toc       bool
+	mux       *http.ServeMux
+}
+
+func newDocsetServer(docsetPath string, toc bool) (*docsetServer, error) {
+	rows, err := readSearchIndex(filepath.Join(docsetPath, "Contents/Resources/docSet.dsidx"))
+	if err != nil {
+		return nil, err
+	}
+	s := &docsetServer{
+		documents: filepath.Join(docsetPath, "Contents/Resources/Documents"),
+		rows:      rows,
+		toc:       toc,
+		mux:       http.NewServeMux(),
+	}
+	s.mux.HandleFunc("GET /api/search", s.handleSearch)
+	s.mux.HandleFunc("GET /api/toc", s.handleTOC)
+	s.mux.HandleFunc("GET /search", s.handleSearchPage)
+	s.mux.HandleFunc("GET /", s.handleDocument)
+	return s, nil
+}
+
+func (s *docsetServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// searchResult is an entry of the /api/search response.
+type searchResult struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+	URL         string `json:"url"`
+}
+
+func (s *docsetServer) handleSearch(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	query := strings.TrimSpace(q.Get("q"))
+	if query == "" {
+		http.Error(w, "missing query parameter q", http.StatusBadRequest)
+		return
+	}
+	limit := 50
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, "invalid limit "+strconv.Quote(v), http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	hits := searchRows(s.rows, query, q["type"])
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	results := make([]searchResult, 0, len(hits))
+	for _, h := range hits {
+		results = append(results, searchResult{
+			Name:        strings.TrimSpace(h.Row.Name),
+			Type:        h.Row.Type,
+			Description: h.Desc,
+			URL:         "/" + h.location(),
+		})
+	}
+	writeJSON(w, results)
+}
+
+// tocEntry is a dashtoc anchor of a page.
+type tocEntry struct {
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Section bool   `json:"section"` // Section is set for the header of a group of entries
+	URL     string `json:"url"`
+}
+
+func (s *docsetServer) handleTOC(w http.ResponseWriter, r *http.Request) {
+	page := strings.TrimPrefix(path.Clean("/"+r.URL.Query().Get("path")), "/")
+	entries, err := s.readTOC(page)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	writeJSON(w, entries)
+}
+
+// readTOC returns the table of contents of a page, from the dashtoc anchors of the page.
+func (s *docsetServer) readTOC(page string) ([]tocEntry, error) {
+	b, err := os.ReadFile(filepath.Join(s.documents, filepath.FromSlash(page)))
+	if err != nil {
+		return nil, fmt.Errorf("page %s not found", page)
+	}
+	return pageTOC(page, b)
+}
+
+func pageTOC(page string, b []byte) ([]tocEntry, error) {
+	root, err := html.Parse(bytes.NewReader(b))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", page)
+	}
+
+	entries := make([]tocEntry, 0)
+	goquery.NewDocumentFromNode(root).Find("a.dashAnchor[name]").Each(func(_ int, a *goquery.Selection) {
+		target, _ := a.Attr("name")
+		ref, ok := strings.CutPrefix(target, "//dash_ref/")
+		if !ok {
+			return
+		}
+		parts := strings.Split(ref, "/")
+		if len(parts) != 3 {
+			return
+		}
+		name, err := url.PathUnescape(parts[1])
+		if err != nil {
+			name = parts[1]
+		}
+		entries = append(entries, tocEntry{
+			Type:    parts[0],
+			Name:    name,
+			Section: parts[2] == "1",
+			URL:     "/" + page + "#" + target,
+		})
+	})
+	return entries, nil
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	_ = enc.Encode(v)
+}
+
+func (s *docsetServer) handleSearchPage(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write([]byte(searchPage))
+}
+
+// handleDocument serves the files of the documentation, adding the table of contents to
+// the HTML pages.
+func (s *docsetServer) handleDocument(w http.ResponseWriter, r *http.Request) {
+	page := strings.TrimPrefix(path.Clean(r.URL.Path), "/")
+	if page == "" {
+		page = "index.html"
+	}
+	if !s.toc || path.Ext(page) != ".html" {
+		http.ServeFile(w, r, filepath.Join(s.documents, filepath.FromSlash(page)))
+		return
+	}
+
+	b, err := os.ReadFile(filepath.Join(s.documents, filepath.FromSlash(page)))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	entries, err := pageTOC(page, b)
+	if err == nil && len(entries) > 0 {
+		var nav bytes.Buffer
+		if err = tocTemplate.Execute(&nav, entries); err == nil {
+			if i := bytes.LastIndex(b, []byte("</body>")); i >= 0 {
+				b = append(b[:i:i], append(nav.Bytes(), b[i:]...)...)
+			}
+		}
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(b)
+}
+
+var tocTemplate = template.Must(template.New("toc").Parse(`<nav id="dash-toc" style="position: fixed; top: 0; right: 0; bottom: 0; width: 16em; overflow-y: auto; padding: 0.5em 1em; background: #fafafa; border-left: 1px solid #ddd; font: 13px/1.5 sans-serif; z-index: 1000">
+<p><a href="/search">Search</a> · <a href="/">Index</a></p>
+<ul style="list-style: none; padding: 0">
+{{range .}}<li{{if not .Section}} style="padding-left: 1em"{{end}}>{{if .Section}}<strong>{{end}}<a href="{{.URL}}">{{.Name}}</a>{{if .Section}}</strong>{{end}}</li>
+{{end}}</ul>
+</nav>
+`))
+
+const searchPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Search</title>
+<style>` + xmlPageStyle + `
+input { font-size: 1.2em; width: 30em; max-width: 100%; }
+.type { color: #777; font-size: 85%; margin-left: 0.5em; }
+</style>
+</head>
+<body>
+<h1>Search</h1>
+<p><input id="q" type="search" placeholder="e.g. add_child or Node.ready" autofocus></p>
+<ul id="results"></ul>
+<script>
+const q = document.getElementById("q");
+const results = document.getElementById("results");
+let pending;
+q.addEventListener("input", () => {
+	clearTimeout(pending);
+	pending = setTimeout(search, 150);
+});
+async function search() {
+	results.replaceChildren();
+	if (q.value.trim() === "") {
+		return;
+	}
+	const res = await fetch("/api/search?q=" + encodeURIComponent(q.value));
+	if (!res.ok) {
+		return;
+	}
+	for (const r of await res.json()) {
+		const li = document.createElement("li");
+		const a = document.createElement("a");
+		a.href = r.url;
+		a.textContent = r.name;
+		const type = document.createElement("span");
+		type.className = "type";
+		type.textContent = r.description ? r.type + " · " + r.description : r.type;
+		li.append(a, type);
+		results.append(li);
+	}
+}
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+const testClassPage = `<!DOCTYPE html>
+<html>
+<head><title>Node</title></head>
+<body>
+<h1>Node</h1>
+<a class="dashAnchor" name="//dash_ref/Section/Methods/1"></a><h2>Methods</h2>
+<a class="dashAnchor" name="//dash_ref/Method/add_child/0"></a><p>add_child</p>
+<a class="dashAnchor" name="//dash_ref/Property/Node%20Name/0"></a><p>name</p>
+</body>
+</html>
+`
+
+// secretContent is the content of the files outside the Documents of the test docset.
+const secretContent = "outside of Documents"
+
+// newTestDocset writes a docset with a single class page and its search index, and a file
+// next to the docset which must never be served.
+func newTestDocset(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	docset := filepath.Join(dir, "Godot.docset")
+	documents := filepath.Join(docset, "Contents/Resources/Documents")
+	if err := os.MkdirAll(filepath.Join(documents, "classes"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		filepath.Join(documents, "classes/class_node.html"): testClassPage,
+		filepath.Join(documents, "style.css"):               "body {}",
+		filepath.Join(docset, "Contents/Info.plist"):        secretContent,
+		filepath.Join(dir, "secret.html"):                   "<html><body>" + secretContent + "</body></html>",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(docset, "Contents/Resources/docSet.dsidx")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.AutoMigrate(&SearchIndex{}); err != nil {
+		t.Fatal(err)
+	}
+	rows := []SearchIndex{
+		{Name: "Node", Type: "Class", Path: "classes/class_node.html"},
+		{Name: "add_child", Type: "Method", Path: makeSearchIndexPath("classes/class_node.html", "add_child", "add_child", "Node", "//dash_ref/Method/add_child/0")},
+		{Name: "Node.add_child", Type: "Method", Path: makeSearchIndexPath("classes/class_node.html", "Node.add_child", "add_child", "Node", "//dash_ref/Method/add_child/0")},
+	}
+	if err = db.Create(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		_ = sqlDB.Close()
+	}
+	return docset
+}
+
+func newTestServer(t *testing.T, toc bool) *httptest.Server {
+	t.Helper()
+	srv, err := newDocsetServer(newTestDocset(t), toc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func get(t *testing.T, url string) (int, string) {
+	t.Helper()
+	res, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res.StatusCode, string(b)
+}
+
+func getJSON(t *testing.T, url string, v any) {
+	t.Helper()
+	code, body := get(t, url)
+	if code != http.StatusOK {
+		t.Fatalf("GET %s: status %d: %s", url, code, body)
+	}
+	if err := json.Unmarshal([]byte(body), v); err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+}
+
+func TestServeSearch(t *testing.T) {
+	ts := newTestServer(t, true)
+
+	var results []map[string]any
+	getJSON(t, ts.URL+"/api/search?q=add_child", &results)
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2: %v", len(results), results)
+	}
+	want := map[string]any{
+		"name":        "add_child",
+		"type":        "Method",
+		"description": "Node",
+		"url":         "/classes/class_node.html#//dash_ref/Method/add_child/0",
+	}
+	for k, v := range want {
+		if results[0][k] != v {
+			t.Errorf("results[0][%q] = %v, want %v", k, results[0][k], v)
+		}
+	}
+	if len(results[0]) != len(want) {
+		t.Errorf("results[0] has fields %v, want %v", results[0], want)
+	}
+
+	results = nil // Unmarshal would merge the results into the previous maps
+	getJSON(t, ts.URL+"/api/search?q=node&type=class", &results)
+	if len(results) != 1 || results[0]["type"] != "Class" || results[0]["url"] != "/classes/class_node.html" {
+		t.Errorf("type filter: got %v, want the Node class", results)
+	}
+	if _, ok := results[0]["description"]; ok {
+		t.Errorf("class result has a description: %v", results[0])
+	}
+
+	getJSON(t, ts.URL+"/api/search?q=node&limit=1", &results)
+	if len(results) != 1 {
+		t.Errorf("limit: got %d results, want 1", len(results))
+	}
+
+	for _, q := range []string{"", "?q=", "?q=node&limit=0", "?q=node&limit=x"} {
+		if code, _ := get(t, ts.URL+"/api/search"+q); code != http.StatusBadRequest {
+			t.Errorf("/api/search%s: status %d, want %d", q, code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestServeTOC(t *testing.T) {
+	ts := newTestServer(t, true)
+
+	var entries []tocEntry
+	getJSON(t, ts.URL+"/api/toc?path=classes/class_node.html", &entries)
+	want := []tocEntry{
+		{Type: "Section", Name: "Methods", Section: true, URL: "/classes/class_node.html#//dash_ref/Section/Methods/1"},
+		{Type: "Method", Name: "add_child", URL: "/classes/class_node.html#//dash_ref/Method/add_child/0"},
+		{Type: "Property", Name: "Node Name", URL: "/classes/class_node.html#//dash_ref/Property/Node%20Name/0"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %v, want %v", entries, want)
+	}
+	for i := range want {
+		if entries[i] != want[i] {
+			t.Errorf("entries[%d] = %v, want %v", i, entries[i], want[i])
+		}
+	}
+
+	if code, _ := get(t, ts.URL+"/api/toc?path=classes/missing.html"); code != http.StatusNotFound {
+		t.Errorf("missing page: status %d, want %d", code, http.StatusNotFound)
+	}
+}
+
+func TestServeSearchPage(t *testing.T) {
+	ts := newTestServer(t, true)
+
+	code, body := get(t, ts.URL+"/search")
+	if code != http.StatusOK || !strings.Contains(body, `<input id="q"`) || !strings.Contains(body, "/api/search?q=") {
+		t.Errorf("/search: status %d, body %q", code, body)
+	}
+}
+
+func TestServeDocument(t *testing.T) {
+	ts := newTestServer(t, true)
+
+	code, body := get(t, ts.URL+"/classes/class_node.html")
+	if code != http.StatusOK {
+		t.Fatalf("status %d, want %d", code, http.StatusOK)
+	}
+	nav := strings.Index(body, `<nav id="dash-toc"`)
+	if nav < 0 || nav > strings.LastIndex(body, "</body>") {
+		t.Fatalf("no table of contents before </body>: %s", body)
+	}
+	if !strings.Contains(body[nav:], `<a href="/classes/class_node.html#//dash_ref/Method/add_child/0">add_child</a>`) {
+		t.Errorf("table of contents without add_child: %s", body[nav:])
+	}
+
+	code, body = get(t, ts.URL+"/style.css")
+	if code != http.StatusOK || body != "body {}" {
+		t.Errorf("/style.css: status %d, body %q", code, body)
+	}
+	if code, _ = get(t, ts.URL+"/classes/missing.html"); code != http.StatusNotFound {
+		t.Errorf("missing page: status %d, want %d", code, http.StatusNotFound)
+	}
+
+	ts = newTestServer(t, false)
+	if _, body = get(t, ts.URL+"/classes/class_node.html"); strings.Contains(body, "dash-toc") {
+		t.Errorf("table of contents added without --toc: %s", body)
+	}
+}
+
+func TestServeTraversal(t *testing.T) {
+	for _, toc := range []bool{true, false} {
+		ts := newTestServer(t, toc)
+		for _, target := range []string{
+			"/../../secret.html",
+			"/..%2f..%2fsecret.html",
+			"/%2e%2e/%2e%2e/secret.html",
+			"/../Info.plist",
+			"/api/toc?path=../../../secret.html",
+		} {
+			// send the request line as is, as clients clean the path
+			status, body := rawGet(t, ts.Listener.Addr().String(), target)
+			if strings.Contains(status, " 200 ") || strings.Contains(body, secretContent) {
+				t.Errorf("toc=%v: GET %s served a file outside Documents: %s %s", toc, target, status, body)
+			}
+		}
+	}
+}
+
+// rawGet sends a GET request for target, without cleaning it, and returns the status line
+// and body of the response.
+func rawGet(t *testing.T, addr, target string) (string, string) {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err = io.WriteString(conn, "GET "+target+" HTTP/1.0\r\nHost: "+addr+"\r\n\r\n"); err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, body, _ := strings.Cut(string(b), "\r\n\r\n")
+	status, _, _ := strings.Cut(head, "\r\n")
+	return status, body
+}
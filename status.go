@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	css "github.com/andybalholm/cascadia"
+	"github.com/samber/lo"
+)
+
+// apiStatus is the stability of a class or member, as marked by the class reference.
+type apiStatus string
+
+const (
+	statusStable       apiStatus = ""
+	statusDeprecated   apiStatus = "deprecated"
+	statusExperimental apiStatus = "experimental"
+)
+
+// statusLabels are the labels that start the note of a deprecated or experimental class
+// or member, e.g. <p><strong>Deprecated:</strong> Use ... instead.</p>, in the English
+// documentation. The notes of the translated documentation are found by the classes of
+// their container, see statusContainers.
+var statusLabels = map[string]apiStatus{
+	"Deprecated:":   statusDeprecated,
+	"Experimental:": statusExperimental,
+}
+
+// Label returns the label of the note of the status, e.g. Deprecated:.
+func (s apiStatus) Label() string {
+	label, _ := lo.FindKey(statusLabels, s)
+	return label
+}
+
+// statusSeparator separates the class from the status in the menu description of a row.
+const statusSeparator = " • "
+
+// menuDescription returns the menu description of a row of class, e.g. Node • deprecated.
+// The rows of classes have no class, so only the status is shown.
+func menuDescription(class string, status apiStatus) string {
+	switch {
+	case status == statusStable:
+		return class
+	case class == "":
+		return string(status)
+	default:
+		return class + statusSeparator + string(status)
+	}
+}
+
+// tocName returns the name of a member in the table of contents of its page.
+func tocName(name string, status apiStatus) string {
+	if status == statusStable {
+		return name
+	}
+	return fmt.Sprintf("%s (%s)", strings.TrimSpace(name), status)
+}
+
+// The values of the --deprecated flag.
+const (
+	deprecatedShow = "show" // index deprecated APIs like any other
+	deprecatedHide = "hide" // do not index deprecated APIs
+)
+
+// statusContainers are the classes of the containers of the deprecated and experimental
+// notes, e.g. <div class="contextual-bg bg-danger docutils container">, which do not
+// depend on the language of the documentation.
+var statusContainers = map[string]apiStatus{
+	"bg-danger":  statusDeprecated,
+	"bg-warning": statusExperimental,
+}
+
+var selStatusLabel = css.MustCompile("strong")
+
+// markedStatus returns the status of the note starting with a status label in s, if any.
+func markedStatus(s *goquery.Selection) apiStatus {
+	var status apiStatus
+	s.FindMatcher(selStatusLabel).AddSelection(s.FilterMatcher(selStatusLabel)).EachWithBreak(func(_ int, l *goquery.Selection) bool {
+		status = labelStatus(l)
+		return status == statusStable
+	})
+	return status
+}
+
+// labelStatus returns the status noted by the label l, which must start a paragraph. A
+// translated label is recognized by the container of its note.
+func labelStatus(l *goquery.Selection) apiStatus {
+	label := strings.TrimSpace(l.Text())
+	if status, ok := statusLabels[label]; ok {
+		return status
+	}
+	if p := l.Parent(); !p.Is("p") || !isLabelled(p) || noteLabel(p) != l.Get(0) {
+		return statusStable
+	}
+	container := l.Closest("div.contextual-bg")
+	for class, status := range statusContainers {
+		if container.HasClass(class) {
+			return status
+		}
+	}
+	return statusStable
+}
+
+// classStatus returns the status of the class with the title h1, which is noted before the
+// sections of the class page.
+func classStatus(h1 *goquery.Selection) apiStatus {
+	var status apiStatus
+	h1.Siblings().Not("section").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		status = markedStatus(s)
+		return status == statusStable
+	})
+	return status
+}
+
+// memberStatus returns the status of the member with the signature n, which is noted in
+// the description following the signature, up to the next member.
+func memberStatus(n *goquery.Selection) apiStatus {
+	for s := n.Next(); s.Length() > 0; s = s.Next() {
+		if class, _ := s.Attr("class"); strings.Contains(class, "classref-") {
+			break
+		}
+		if status := markedStatus(s); status != statusStable {
+			return status
+		}
+	}
+	return statusStable
+}
+
+// xmlStatus returns the status of a class or member of the class reference XML and the
+// BBCode of its note.
+func xmlStatus(deprecated, experimental *string) (apiStatus, string) {
+	switch {
+	case deprecated != nil:
+		return statusDeprecated, *deprecated
+	case experimental != nil:
+		return statusExperimental, *experimental
+	}
+	return statusStable, ""
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	css "github.com/andybalholm/cascadia"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// values of the --code-samples flag
+const (
+	samplesTabs     = "tabs"     // keep the Sphinx tabs
+	samplesStatic   = "static"   // show every tab, one after the other
+	samplesGDScript = "gdscript" // only keep the GDScript tabs
+	samplesCSharp   = "csharp"   // only keep the C# tabs
+)
+
+// sampleTabs are the titles of the tabs kept by the --code-samples values for a language.
+var sampleTabs = map[string]string{
+	samplesGDScript: "GDScript",
+	samplesCSharp:   "C#",
+}
+
+var (
+	selTabSets   = css.MustCompile("div.sphinx-tabs")
+	selTabList   = css.MustCompile(`div[role="tablist"]`)
+	selTabPanels = css.MustCompile("div.sphinx-tabs-panel")
+)
+
+// selectCodeSamples replaces the Sphinx tabs of a page, which need JavaScript, as chosen by
+// --code-samples. Tab sets without a tab for the language are shown as static tabs.
+func selectCodeSamples(doc *goquery.Document) {
+	doc.FindMatcher(selTabSets).Each(func(_ int, set *goquery.Selection) {
+		titles := make(map[*html.Node]string)
+		set.FindMatcher(selTabPanels).Each(func(_ int, panel *goquery.Selection) {
+			id := panel.AttrOr("aria-labelledby", "")
+			titles[panel.Get(0)] = strings.TrimSpace(set.Find(`button[id="` + id + `"]`).Text())
+		})
+
+		keep := sampleTabs[samples]
+		panels := set.FindMatcher(selTabPanels)
+		if keep != "" && panels.FilterFunction(func(_ int, p *goquery.Selection) bool { return titles[p.Get(0)] == keep }).Length() > 0 {
+			panels.FilterFunction(func(_ int, p *goquery.Selection) bool { return titles[p.Get(0)] != keep }).Remove()
+		} else {
+			// label each tab, as the tab list is removed
+			panels.Each(func(_ int, p *goquery.Selection) {
+				label := &html.Node{Type: html.ElementNode, DataAtom: atom.P, Data: atom.P.String(), Attr: []html.Attribute{{Key: "class", Val: "sphinx-tabs-title"}}}
+				strong := &html.Node{Type: html.ElementNode, DataAtom: atom.Strong, Data: atom.Strong.String()}
+				strong.AppendChild(&html.Node{Type: html.TextNode, Data: titles[p.Get(0)]})
+				label.AppendChild(strong)
+				p.Get(0).InsertBefore(label, p.Get(0).FirstChild)
+			})
+		}
+
+		set.FindMatcher(selTabList).Remove()
+		set.FindMatcher(selTabPanels).RemoveAttr("hidden").RemoveAttr("role").RemoveAttr("tabindex")
+	})
+}
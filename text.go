@@ -0,0 +1,186 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// blockElements start on a new line when converted to text.
+var blockElements = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Pre: true, atom.Section: true, atom.Table: true, atom.Tr: true,
+	atom.Ul: true, atom.Ol: true, atom.Li: true, atom.Dl: true, atom.Dt: true, atom.Dd: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Blockquote: true, atom.Hr: true, atom.Br: true,
+}
+
+// plainText returns the text of the nodes of s, with block elements on their own lines,
+// list items prefixed with "- " and the whitespace of preformatted text kept.
+func plainText(s *goquery.Selection) string {
+	var sb strings.Builder
+	for _, n := range s.Nodes {
+		writeText(&sb, n, false)
+	}
+	return squeezeLines(sb.String())
+}
+
+func writeText(sb *strings.Builder, n *html.Node, pre bool) {
+	switch n.Type {
+	case html.TextNode:
+		text := n.Data
+		if !pre {
+			text = spaces.ReplaceAllString(text, " ")
+			if endsWithSpace(sb) {
+				text = strings.TrimPrefix(text, " ")
+			}
+		}
+		sb.WriteString(text)
+		return
+	case html.ElementNode:
+		if skipText(n) {
+			return
+		}
+	case html.DocumentNode:
+	default:
+		return
+	}
+
+	block := blockElements[n.DataAtom]
+	if block {
+		sb.WriteString("\n")
+		if n.DataAtom == atom.P || n.DataAtom == atom.Pre {
+			sb.WriteString("\n")
+		}
+	}
+	if n.DataAtom == atom.Li {
+		sb.WriteString("- ")
+	}
+	pre = pre || n.DataAtom == atom.Pre
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		writeText(sb, c, pre)
+	}
+	if block {
+		sb.WriteString("\n")
+	}
+}
+
+// skipText reports whether the element is not part of the text, such as the ¶ links of
+// Sphinx headers, the buttons of Sphinx tabs and the dashtoc anchors.
+func skipText(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.Script, atom.Style, atom.Head:
+		return true
+	case atom.Div:
+		return attr(n, "role") == "tablist"
+	case atom.A:
+		class := attr(n, "class")
+		return strings.Contains(class, "headerlink") || strings.Contains(class, "dashAnchor")
+	}
+	return false
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+var spaces = regexp.MustCompile(`\s+`)
+
+func endsWithSpace(sb *strings.Builder) bool {
+	s := sb.String()
+	return s == "" || s[len(s)-1] == ' ' || s[len(s)-1] == '\n'
+}
+
+// squeezeLines trims trailing whitespace from each line and collapses consecutive blank
+// lines.
+func squeezeLines(s string) string {
+	lines := strings.Split(s, "\n")
+	out := lines[:0]
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		if line == "" && (len(out) == 0 || out[len(out)-1] == "") {
+			continue
+		}
+		out = append(out, line)
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
+
+// memberDescription returns the signature and description of the member with the dashtoc
+// anchor, which precedes the signature, up to the next member.
+func memberDescription(anchor *goquery.Selection) *goquery.Selection {
+	sig := anchor.Next()
+	if p := anchor.Parent(); p.Is("p") {
+		// the anchors of enum values are inside the signature
+		sig = p
+	}
+	if sig.Length() == 0 {
+		return sig
+	}
+
+	desc := sig
+	for s := sig.Next(); s.Length() > 0; s = s.Next() {
+		if s.Is("a.dashAnchor, hr, h2, h3") {
+			break
+		}
+		if class, _ := s.Attr("class"); strings.Contains(class, "classref-") {
+			break
+		}
+		desc = desc.AddSelection(s)
+	}
+	return desc
+}
+
+// classDescription returns the description section of a class page, without its header.
+func classDescription(doc *goquery.Document) *goquery.Selection {
+	sec := doc.Find("section#description").First()
+	return sec.Children().Not("h2, a.dashAnchor")
+}
+
+// classBrief returns the brief description of the class with the title h1, the first
+// paragraph before the sections of the class page that is not a labelled note, such as
+// "Inherits:".
+func classBrief(h1 *goquery.Selection) string {
+	var brief string
+	h1.NextAllFiltered("p").EachWithBreak(func(_ int, p *goquery.Selection) bool {
+		if isLabelled(p) {
+			return true
+		}
+		brief = plainText(p)
+		return false
+	})
+	return brief
+}
+
+// isLabelled reports whether the paragraph p is a labelled note of a class page, such as
+// "Inherits:" or "Inherited By:". The labels of some languages end with a full-width colon.
+func isLabelled(p *goquery.Selection) bool {
+	label := noteLabel(p)
+	if label == nil {
+		return false
+	}
+	text := strings.TrimSpace(nodeText(label))
+	return strings.HasSuffix(text, ":") || strings.HasSuffix(text, "：")
+}
+
+// noteLabel returns the <strong> element starting the paragraph p, if any.
+func noteLabel(p *goquery.Selection) *html.Node {
+	if p.Length() == 0 {
+		return nil
+	}
+	n := p.Get(0).FirstChild
+	for n != nil && n.Type == html.TextNode && strings.TrimSpace(n.Data) == "" {
+		n = n.NextSibling
+	}
+	if n == nil || n.DataAtom != atom.Strong {
+		return nil
+	}
+	return n
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// docsInput is a --docs-path argument, optionally tagged with the Godot version of the
+// documentation.
+type docsInput struct {
+	Version string
+	Path    string
+}
+
+// parseDocsInputs parses the --docs-path arguments, which are either PATH or VERSION=PATH.
+// Building more than one docset requires every path to be tagged with a unique version.
+func parseDocsInputs(values []string) ([]docsInput, error) {
+	inputs := make([]docsInput, 0, len(values))
+	versions := make(map[string]struct{})
+	for _, v := range values {
+		in := parseDocsInput(v)
+		if len(values) > 1 {
+			if in.Version == "" {
+				return nil, fmt.Errorf("--docs-path %q must be tagged with a version, e.g. 4.3=%s", v, v)
+			}
+			if _, ok := versions[in.Version]; ok {
+				return nil, fmt.Errorf("duplicate version %q", in.Version)
+			}
+			versions[in.Version] = struct{}{}
+		}
+		inputs = append(inputs, in)
+	}
+	return inputs, nil
+}
+
+// parseDocsInput parses a path that is optionally tagged with a version, as VERSION=PATH.
+func parseDocsInput(v string) docsInput {
+	if ver, path, ok := strings.Cut(v, "="); ok && ver != "" && !strings.ContainsAny(ver, `/\`) {
+		return docsInput{Version: ver, Path: path}
+	}
+	return docsInput{Path: v}
+}
+
+// compareVersions orders Godot versions numerically, e.g. 4.10 is after 4.9.
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aerr := strconv.Atoi(as[i])
+		bn, berr := strconv.Atoi(bs[i])
+		if aerr != nil || berr != nil {
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+			continue
+		}
+		if an != bn {
+			return an - bn
+		}
+	}
+	return len(as) - len(bs)
+}
+
+// bundle describes the Info.plist of a docset.
+type bundle struct {
+	Lang    locale
+	Version string
+}
+
+// BundleID returns the identifier of the docset, which is unique per language and version.
+func (b bundle) BundleID() string {
+	id := "godot"
+	if !b.Lang.isEnglish() {
+		id += "-" + b.Lang.Code
+	}
+	if b.Version != "" {
+		id += "-" + b.Version
+	}
+	return id
+}
+
+// BundleName returns the name of the docset shown by Dash, e.g. "Godot 4.3 (Français)".
+func (b bundle) BundleName() string {
+	name := "Godot"
+	if b.Version != "" {
+		name += " " + b.Version
+	}
+	if !b.Lang.isEnglish() {
+		name += " (" + b.Lang.Name + ")"
+	}
+	return name
+}
+
+// DocsURL returns the URL of the online documentation for the language and version.
+func (b bundle) DocsURL() string {
+	ver := "stable"
+	if b.Version != "" {
+		ver = b.Version
+	}
+	return "https://docs.godotengine.org/" + b.Lang.urlCode() + "/" + ver + "/"
+}
+
+// buildVersions builds a docset for each version in outputPath/<version>/Godot.docset.
+// The documentation is copied into each docset, with identical assets shared between the
+// versions as hard links.
+func buildVersions(ctx context.Context, inputs []docsInput, outputPath string, l locale) error {
+	slices.SortFunc(inputs, func(a, b docsInput) int { return -compareVersions(a.Version, b.Version) })
+
+	shared := make(map[string]string)
+	hoverName := filepath.Base(hoverPath)
+	for _, in := range inputs {
+		slog.Info("Build version.", "version", in.Version, "path", in.Path)
+
+		cfg := buildConfig{
+			DocsPath:   in.Path,
+			DocsetPath: filepath.Join(outputPath, in.Version, "Godot.docset"),
+			Version:    in.Version,
+			Lang:       l,
+		}
+		if hoverName != "." {
+			// every version has its own hover database, next to its docset
+			hoverPath = filepath.Join(outputPath, in.Version, hoverName)
+		}
+
+		if err := os.RemoveAll(cfg.DocsetPath); err != nil {
+			return errors.Wrapf(err, "failed to remove %s", cfg.DocsetPath)
+		}
+		if err := copyDocs(cfg.DocsPath, filepath.Join(cfg.DocsetPath, "Contents/Resources/Documents"), shared); err != nil {
+			return err
+		}
+		if err := buildDocset(ctx, cfg); err != nil {
+			return errors.Wrapf(err, "failed to build version %s", in.Version)
+		}
+	}
+
+	if feedURL == "" {
+		return nil
+	}
+
+	for _, in := range inputs {
+		dir := filepath.Join(outputPath, in.Version)
+		if err := writeArchive(filepath.Join(dir, "Godot.docset"), filepath.Join(dir, "Godot.tgz")); err != nil {
+			return err
+		}
+	}
+	return writeFeed(filepath.Join(outputPath, "Godot.xml"), inputs)
+}
+
+// copyDocs copies the documentation at src to dst. HTML files are copied, as they are
+// rewritten while building the docset, while other files are hard linked to an identical
+// file of a previously copied version, if there is one. shared maps the content hash of
+// the files copied so far to their path.
+func copyDocs(src, dst string, shared map[string]string) error {
+	var linked int
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		if filepath.Ext(path) == ".html" {
+			return copyFile(path, target)
+		}
+
+		sum, err := hashFile(path)
+		if err != nil {
+			return err
+		}
+		if prev, ok := shared[sum]; ok {
+			if os.Link(prev, target) == nil {
+				linked++
+				return nil
+			}
+			// different file systems, so fall back to a copy
+		}
+		shared[sum] = target
+		return copyFile(path, target)
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to copy %s", src)
+	}
+	slog.Info("Copied documentation.", "path", src, "shared", linked)
+	return nil
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// writeArchive writes the docset at src to a gzipped tarball, as expected by Dash feeds.
+func writeArchive(src, dest string) error {
+	slog.Info("Write archive.", "path", dest)
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = out.Close() }()
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+
+	base := filepath.Dir(src)
+	err = filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(base, path)
+		if err != nil {
+			return err
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if err = tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = f.Close() }()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err == nil {
+		err = tw.Close()
+	}
+	if err == nil {
+		err = gz.Close()
+	}
+	return errors.Wrapf(err, "failed to write %s", dest)
+}
+
+// writeFeed writes a Dash feed, with the newest version as the main entry and the other
+// versions listed as other versions.
+func writeFeed(dest string, inputs []docsInput) error {
+	type feedVersion struct {
+		Name string `xml:"name"`
+	}
+	type feedEntry struct {
+		XMLName       xml.Name      `xml:"entry"`
+		Version       string        `xml:"version"`
+		URL           string        `xml:"url"`
+		OtherVersions []feedVersion `xml:"other-versions>version"`
+	}
+
+	base := strings.TrimSuffix(feedURL, "/")
+	entry := feedEntry{
+		Version: inputs[0].Version,
+		URL:     base + "/" + inputs[0].Version + "/Godot.tgz",
+	}
+	for _, in := range inputs {
+		entry.OtherVersions = append(entry.OtherVersions, feedVersion{Name: in.Version})
+	}
+
+	b, err := xml.MarshalIndent(entry, "", "\t")
+	if err != nil {
+		return err
+	}
+	return writeFile(dest, append(b, '\n'))
+}
@@ -0,0 +1,722 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// The types in this file model the class reference XML files kept in the Godot engine
+// repository (doc/classes/*.xml and modules/*/doc_classes/*.xml).
+
+type xmlClass struct {
+	XMLName          xml.Name       `xml:"class"`
+	Name             string         `xml:"name,attr"`
+	Inherits         string         `xml:"inherits,attr"`
+	Deprecated       *string        `xml:"deprecated,attr"`
+	Experimental     *string        `xml:"experimental,attr"`
+	BriefDescription string         `xml:"brief_description"`
+	Description      string         `xml:"description"`
+	Tutorials        []xmlLink      `xml:"tutorials>link"`
+	Constructors     []xmlMethod    `xml:"constructors>constructor"`
+	Methods          []xmlMethod    `xml:"methods>method"`
+	Operators        []xmlMethod    `xml:"operators>operator"`
+	Members          []xmlMember    `xml:"members>member"`
+	Signals          []xmlMethod    `xml:"signals>signal"`
+	Constants        []xmlConstant  `xml:"constants>constant"`
+	Annotations      []xmlMethod    `xml:"annotations>annotation"`
+	ThemeItems       []xmlThemeItem `xml:"theme_items>theme_item"`
+
+	// FilePath is the path of the XML file the class was read from.
+	FilePath string `xml:"-"`
+	// EntryType overrides the Dash entry type of the class, such as for extension classes.
+	EntryType string `xml:"-"`
+}
+
+type xmlLink struct {
+	Title string `xml:"title,attr"`
+	URL   string `xml:",chardata"`
+}
+
+// xmlMethod models methods, constructors, operators, signals and annotations,
+// which all share the same structure.
+type xmlMethod struct {
+	Name         string     `xml:"name,attr"`
+	Qualifiers   string     `xml:"qualifiers,attr"`
+	Deprecated   *string    `xml:"deprecated,attr"`
+	Experimental *string    `xml:"experimental,attr"`
+	Return       *xmlReturn `xml:"return"`
+	Params       []xmlParam `xml:"param"`
+	Description  string     `xml:"description"`
+}
+
+type xmlReturn struct {
+	Type string `xml:"type,attr"`
+	Enum string `xml:"enum,attr"`
+}
+
+type xmlParam struct {
+	Index   int    `xml:"index,attr"`
+	Name    string `xml:"name,attr"`
+	Type    string `xml:"type,attr"`
+	Enum    string `xml:"enum,attr"`
+	Default string `xml:"default,attr"`
+}
+
+type xmlMember struct {
+	Name         string  `xml:"name,attr"`
+	Type         string  `xml:"type,attr"`
+	Enum         string  `xml:"enum,attr"`
+	Setter       string  `xml:"setter,attr"`
+	Getter       string  `xml:"getter,attr"`
+	Default      *string `xml:"default,attr"`
+	Deprecated   *string `xml:"deprecated,attr"`
+	Experimental *string `xml:"experimental,attr"`
+	Description  string  `xml:",chardata"`
+}
+
+type xmlConstant struct {
+	Name         string  `xml:"name,attr"`
+	Value        string  `xml:"value,attr"`
+	Enum         string  `xml:"enum,attr"`
+	IsBitfield   bool    `xml:"is_bitfield,attr"`
+	Deprecated   *string `xml:"deprecated,attr"`
+	Experimental *string `xml:"experimental,attr"`
+	Description  string  `xml:",chardata"`
+}
+
+type xmlThemeItem struct {
+	Name        string `xml:"name,attr"`
+	DataType    string `xml:"data_type,attr"`
+	Type        string `xml:"type,attr"`
+	Default     string `xml:"default,attr"`
+	Description string `xml:",chardata"`
+}
+
+// typeName returns the displayed type of a parameter or return value, preferring the enum.
+func typeName(typ, enum string) string {
+	if enum != "" {
+		return enum
+	}
+	return typ
+}
+
+// Signature formats the method the same way the rendered class reference does,
+// e.g. "add_child(node: Node, force_readable_name: bool = false)".
+func (m *xmlMethod) Signature() string {
+	var sb strings.Builder
+	sb.WriteString(m.Name)
+	sb.WriteByte('(')
+	params := slices.Clone(m.Params)
+	slices.SortStableFunc(params, func(a, b xmlParam) int { return a.Index - b.Index })
+	for i, p := range params {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(p.Name)
+		sb.WriteString(": ")
+		sb.WriteString(typeName(p.Type, p.Enum))
+		if p.Default != "" {
+			sb.WriteString(" = ")
+			sb.WriteString(p.Default)
+		}
+	}
+	if strings.Contains(m.Qualifiers, "vararg") {
+		if len(params) > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString("...")
+	}
+	sb.WriteByte(')')
+	if q := strings.TrimSpace(strings.ReplaceAll(m.Qualifiers, "vararg", "")); q != "" {
+		sb.WriteByte(' ')
+		sb.WriteString(q)
+	}
+	return sb.String()
+}
+
+// ReturnType returns the displayed return type, or "void".
+func (m *xmlMethod) ReturnType() string {
+	if m.Return == nil {
+		return "void"
+	}
+	return typeName(m.Return.Type, m.Return.Enum)
+}
+
+// classFileName returns the file name of the page for a class, which matches the name
+// used by the Sphinx class reference, e.g. class_node.html.
+func classFileName(name string) string {
+	return "class_" + strings.ToLower(name) + ".html"
+}
+
+// anchorID returns an element id in the style of the Sphinx class reference,
+// e.g. class-node-method-add-child.
+func anchorID(parts ...string) string {
+	id := strings.ToLower(strings.Join(parts, "-"))
+	return strings.NewReplacer("_", "-", " ", "-", ".", "-").Replace(id)
+}
+
+// readXMLClasses reads every class XML file in dirs.
+func readXMLClasses(ctx context.Context, dirs []string) ([]xmlClass, error) {
+	var paths []string
+	for _, dir := range dirs {
+		matches, err := filepath.Glob(filepath.Join(dir, "*.xml"))
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			slog.Warn("No class XML files found.", "path", dir)
+		}
+		paths = append(paths, matches...)
+	}
+
+	classes := make([]xmlClass, len(paths))
+	err := executor.ForWeightedWithContext(ctx, len(paths), func(i int) int64 { return fileSize(paths[i]) }, func(_ context.Context, i, _ int) error {
+		b, err := os.ReadFile(paths[i])
+		if err != nil {
+			return fmt.Errorf("failed to read file: %w", err)
+		}
+		if err = xml.Unmarshal(b, &classes[i]); err != nil {
+			return errors.Wrapf(err, "failed to parse XML in %s", paths[i])
+		}
+		classes[i].FilePath = paths[i]
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// a class may be documented in more than one directory
+	result := uniqueClasses(classes)
+	slices.SortFunc(result, func(a, b xmlClass) int { return strings.Compare(a.Name, b.Name) })
+
+	return result, nil
+}
+
+// uniqueClasses returns the classes with a single class per name. The last class with a
+// name replaces the previous ones, in the position of the first.
+func uniqueClasses(classes []xmlClass) []xmlClass {
+	seen := make(map[string]int, len(classes))
+	result := make([]xmlClass, 0, len(classes))
+	for _, c := range classes {
+		if i, ok := seen[c.Name]; ok {
+			slog.Warn("Duplicate class.", "class", c.Name, "path", c.FilePath, "previous", result[i].FilePath)
+			result[i] = c
+			continue
+		}
+		seen[c.Name] = len(result)
+		result = append(result, c)
+	}
+	return result
+}
+
+// xmlClassSet is the set of classes read from XML, with their inheritance.
+type xmlClassSet struct {
+	classes  []xmlClass
+	byName   map[string]*xmlClass
+	children map[string][]string
+}
+
+func newXMLClassSet(classes []xmlClass) *xmlClassSet {
+	s := &xmlClassSet{
+		classes:  classes,
+		byName:   make(map[string]*xmlClass, len(classes)),
+		children: make(map[string][]string),
+	}
+	for i := range classes {
+		c := &classes[i]
+		s.byName[c.Name] = c
+		if c.Inherits != "" {
+			s.children[c.Inherits] = append(s.children[c.Inherits], c.Name)
+		}
+	}
+	return s
+}
+
+// ancestors returns the inheritance chain of a class, starting with its parent.
+func (s *xmlClassSet) ancestors(name string) []string {
+	var chain []string
+	for c := s.byName[name]; c != nil && c.Inherits != ""; c = s.byName[c.Inherits] {
+		if slices.Contains(chain, c.Inherits) {
+			break // guard against cycles in hand written docs
+		}
+		chain = append(chain, c.Inherits)
+	}
+	return chain
+}
+
+// entryType returns the Dash entry type of a class, matching the sections of the
+// Sphinx classes/index.html page.
+func (s *xmlClassSet) entryType(name string) string {
+	if c := s.byName[name]; c != nil && c.EntryType != "" {
+		return c.EntryType
+	}
+	if strings.HasPrefix(name, "@") {
+		return "Global"
+	}
+	chain := append([]string{name}, s.ancestors(name)...)
+	switch {
+	case slices.Contains(chain, "Node"):
+		return "Class"
+	case slices.Contains(chain, "Resource"):
+		return "Resource"
+	case slices.Contains(chain, "Object"):
+		return "Object"
+	default:
+		return "Type"
+	}
+}
+
+// xmlPageSection is a section of a rendered class page, with its dashtoc header.
+type xmlPageSection struct {
+	Title  string
+	ID     string
+	Target string
+	Body   template.HTML
+	Items  []xmlPageItem
+}
+
+// xmlPageItem is a single member of a class, with its dashtoc anchor.
+type xmlPageItem struct {
+	ID          string
+	Target      string
+	Signature   string
+	Value       string
+	URL         string
+	Description template.HTML
+	Status      apiStatus
+	Note        template.HTML // Note is the deprecated or experimental note of the member
+}
+
+type xmlClassRef struct {
+	Name string
+	Href string
+}
+
+type xmlPage struct {
+	Name         string
+	ID           string
+	Links        []string
+	Anchors      []string
+	Inherits     []xmlClassRef
+	InheritedBy  []xmlClassRef
+	Brief        template.HTML
+	Status       apiStatus
+	Note         template.HTML
+	Sections     []xmlPageSection
+	ClassTargets []string
+	FilePath     string
+	Rows         []SearchIndex
+	idCount      map[string]int
+}
+
+// uniqueID returns id, with a numeric suffix if it was already used on the page,
+// as overloaded constructors and operators share a name.
+func (p *xmlPage) uniqueID(id string) string {
+	n := p.idCount[id]
+	p.idCount[id] = n + 1
+	if n == 0 {
+		return id
+	}
+	return fmt.Sprintf("%s-%d", id, n)
+}
+
+// addSection adds a section header to the page and its dashtoc link.
+func (p *xmlPage) addSection(title, id, etype string) *xmlPageSection {
+	target := sectionTarget(title, etype, true)
+	p.Links = append(p.Links, target)
+	p.Sections = append(p.Sections, xmlPageSection{
+		Title:  title,
+		ID:     id,
+		Target: target,
+	})
+	return &p.Sections[len(p.Sections)-1]
+}
+
+// addItem adds a member to section, along with its dashtoc link and search index row.
+// Members of a deprecated or experimental class share its status, unless noted otherwise.
+func (p *xmlPage) addItem(sec *xmlPageSection, name, etype string, item xmlPageItem) {
+	if item.Status == statusStable {
+		item.Status = p.Status
+	}
+	item.Target = sectionTarget(tocName(name, item.Status), etype, false)
+	item.ID = p.uniqueID(item.ID)
+	p.Links = append(p.Links, item.Target)
+	sec.Items = append(sec.Items, item)
+	p.Rows = append(p.Rows, newMemberRow(p.FilePath, name, etype, p.Name, item.Target, item.Status))
+}
+
+// statusItem returns item with the status and note of a deprecated or experimental member.
+func statusItem(bb *bbcodeRenderer, item xmlPageItem, deprecated, experimental *string) xmlPageItem {
+	item.Status, item.Note = statusNote(bb, deprecated, experimental)
+	return item
+}
+
+// statusNote returns the status of a class or member and its note, e.g. Deprecated: Use ... instead.
+func statusNote(bb *bbcodeRenderer, deprecated, experimental *string) (apiStatus, template.HTML) {
+	status, note := xmlStatus(deprecated, experimental)
+	if status == statusStable {
+		return status, ""
+	}
+	return status, bb.render("[b]" + status.Label() + "[/b] " + strings.TrimSpace(note))
+}
+
+// buildXMLPage builds the page model and search index rows for a class.
+func buildXMLPage(set *xmlClassSet, c *xmlClass) *xmlPage {
+	p := &xmlPage{
+		Name:     c.Name,
+		ID:       anchorID("class", c.Name),
+		FilePath: "classes/" + classFileName(c.Name),
+		idCount:  make(map[string]int),
+	}
+	bb := newBBCodeRenderer(set, c.Name)
+
+	// class name
+	p.ClassTargets = []string{sectionTarget(c.Name, "Class", true), sectionTarget(c.Name, "Class", false)}
+	p.Links = append(p.Links, p.ClassTargets...)
+
+	for _, name := range set.ancestors(c.Name) {
+		p.Inherits = append(p.Inherits, set.ref(name))
+	}
+	for _, name := range set.children[c.Name] {
+		p.InheritedBy = append(p.InheritedBy, set.ref(name))
+	}
+	p.Brief = bb.render(c.BriefDescription)
+	p.Status, p.Note = statusNote(bb, c.Deprecated, c.Experimental)
+
+	if strings.TrimSpace(c.Description) != "" {
+		sec := p.addSection("Description", "description", "Section")
+		sec.Body = bb.render(c.Description)
+	}
+
+	if len(c.Tutorials) > 0 {
+		sec := p.addSection("Tutorials", "tutorials", "Guide")
+		for _, t := range c.Tutorials {
+			title := t.Title
+			url := tutorialURL(strings.TrimSpace(t.URL))
+			if title == "" {
+				title = url
+			}
+			// tutorials are not added to the search index
+			target := sectionTarget(title, "Guide", false)
+			p.Links = append(p.Links, target)
+			sec.Items = append(sec.Items, xmlPageItem{Target: target, Signature: title, URL: url})
+		}
+	}
+
+	if len(c.Members) > 0 {
+		sec := p.addSection("Properties", "property-descriptions", "Property")
+		for _, m := range c.Members {
+			item := statusItem(bb, xmlPageItem{
+				ID:          anchorID("class", c.Name, "property", m.Name),
+				Signature:   typeName(m.Type, m.Enum) + " " + m.Name,
+				Description: bb.render(m.Description),
+			}, m.Deprecated, m.Experimental)
+			if m.Default != nil {
+				item.Value = *m.Default
+			}
+			p.addItem(sec, m.Name, "Property", item)
+		}
+	}
+
+	methods := func(title, id, etype, kind string, list []xmlMethod) {
+		if len(list) == 0 {
+			return
+		}
+		sec := p.addSection(title, id, etype)
+		for i := range list {
+			m := &list[i]
+			sig := m.Signature()
+			p.addItem(sec, sig, etype, statusItem(bb, xmlPageItem{
+				ID:          anchorID("class", c.Name, kind, m.Name),
+				Signature:   m.ReturnType() + " " + sig,
+				Description: bb.render(m.Description),
+			}, m.Deprecated, m.Experimental))
+		}
+	}
+	methods("Constructors", "constructor-descriptions", "Constructor", "constructor", c.Constructors)
+	methods("Methods", "method-descriptions", "Method", "method", c.Methods)
+	methods("Operators", "operator-descriptions", "Operator", "operator", c.Operators)
+	methods("Annotations", "annotations", "Annotation", "annotation", c.Annotations)
+
+	if len(c.ThemeItems) > 0 {
+		sec := p.addSection("Theme Properties", "theme-property-descriptions", "Style")
+		for _, t := range c.ThemeItems {
+			p.addItem(sec, t.Name, "Style", xmlPageItem{
+				ID:          anchorID("class", c.Name, "theme", t.DataType, t.Name),
+				Signature:   t.Type + " " + t.Name,
+				Value:       t.Default,
+				Description: bb.render(t.Description),
+			})
+		}
+	}
+
+	if len(c.Signals) > 0 {
+		sec := p.addSection("Signals", "signals", "Signal")
+		for i := range c.Signals {
+			s := &c.Signals[i]
+			p.addItem(sec, s.Name, "Signal", statusItem(bb, xmlPageItem{
+				ID:          anchorID("class", c.Name, "signal", s.Name),
+				Signature:   s.Signature(),
+				Description: bb.render(s.Description),
+			}, s.Deprecated, s.Experimental))
+		}
+	}
+
+	// enumerations, with each value named <enum>.<value>
+	var enums []string
+	for _, k := range c.Constants {
+		if k.Enum != "" && !slices.Contains(enums, k.Enum) {
+			enums = append(enums, k.Enum)
+		}
+	}
+	if len(enums) > 0 {
+		sec := p.addSection("Enumerations", "enumerations", "Enum")
+		for _, enum := range enums {
+			p.addItem(sec, enum, "Enum", xmlPageItem{
+				ID:        anchorID("enum", c.Name, enum),
+				Signature: "enum " + enum,
+			})
+			for _, k := range c.Constants {
+				if k.Enum != enum {
+					continue
+				}
+				p.addItem(sec, enum+"."+k.Name, "Enum", statusItem(bb, xmlPageItem{
+					ID:          anchorID("class", c.Name, "constant", k.Name),
+					Signature:   k.Name,
+					Value:       k.Value,
+					Description: bb.render(k.Description),
+				}, k.Deprecated, k.Experimental))
+			}
+		}
+	}
+
+	constants := lo.Filter(c.Constants, func(k xmlConstant, _ int) bool { return k.Enum == "" })
+	if len(constants) > 0 {
+		sec := p.addSection("Constants", "constants", "Constant")
+		for _, k := range constants {
+			p.addItem(sec, k.Name, "Constant", statusItem(bb, xmlPageItem{
+				ID:          anchorID("class", c.Name, "constant", k.Name),
+				Signature:   k.Name,
+				Value:       k.Value,
+				Description: bb.render(k.Description),
+			}, k.Deprecated, k.Experimental))
+		}
+	}
+
+	return p
+}
+
+// ref returns a link to the page of a class. Classes outside of the set that are part of the
+// docset, such as the base classes of extensions, are linked to their dashtoc class anchor.
+func (s *xmlClassSet) ref(name string) xmlClassRef {
+	ref := xmlClassRef{Name: name}
+	if _, ok := s.byName[name]; ok {
+		ref.Href = classFileName(name)
+	} else if _, ok := knownClasses[name]; ok {
+		ref.Href = classFileName(name) + "#" + sectionTarget(name, "Class", false)
+	}
+	return ref
+}
+
+// tutorialURL resolves the $DOCS_URL placeholder used by tutorial links in the class XML.
+func tutorialURL(link string) string {
+	const docsURL = "$DOCS_URL/"
+	rel, ok := strings.CutPrefix(link, docsURL)
+	if !ok {
+		return link
+	}
+	if docsPath == "" {
+		return bundle{Lang: lang, Version: version}.DocsURL() + rel
+	}
+	// the tutorials are part of this docset
+	u, err := url.Parse(rel)
+	if err != nil {
+		return link
+	}
+	u.Path = "../" + strings.TrimSuffix(u.Path, ".html") + ".html"
+	return u.String()
+}
+
+// processXMLClasses builds the class pages and search index from the class reference XML.
+func processXMLClasses(ctx context.Context) (*xmlClassSet, error) {
+	slog.Info("Process class XML", "paths", xmlPaths)
+
+	classes, err := readXMLClasses(ctx, xmlPaths)
+	if err != nil {
+		return nil, err
+	}
+	classes = selectXMLClasses(classes)
+	set := newXMLClassSet(classes)
+	for _, c := range classes {
+		knownClasses[c.Name] = struct{}{}
+	}
+
+	return set, writeXMLClasses(ctx, set)
+}
+
+// selectXMLClasses returns the classes to build, without those left out by the command
+// line options, as done by processClasses for the class pages.
+func selectXMLClasses(classes []xmlClass) []xmlClass {
+	return lo.Filter(classes, func(c xmlClass, _ int) bool {
+		// the GDScript globals do not apply to C#
+		if samples == samplesCSharp && c.Name == "@GDScript" {
+			return false
+		}
+		return includePath("classes/" + classFileName(c.Name))
+	})
+}
+
+// writeXMLClasses writes the page and search index rows of every class in set.
+func writeXMLClasses(ctx context.Context, set *xmlClassSet) error {
+	classes := set.classes
+	pages := make([]*xmlPage, len(classes))
+	err := executor.ForWeightedWithContext(ctx, len(classes), func(i int) int64 { return fileSize(classes[i].FilePath) }, func(_ context.Context, i, _ int) error {
+		c := &classes[i]
+		slog.Info("Processing file.", "class", c.Name, "path", c.FilePath)
+
+		p := buildXMLPage(set, c)
+		pages[i] = p
+
+		var buf bytes.Buffer
+		if err := xmlClassTemplate.Execute(&buf, p); err != nil {
+			return errors.Wrapf(err, "failed to render HTML for %s", c.Name)
+		}
+		return writeFile(filepath.Join(targetPath, p.FilePath), buf.Bytes())
+	})
+	if err != nil {
+		return err
+	}
+
+	var rows []SearchIndex
+	for _, p := range pages {
+		rows = append(rows, newClassRow(p.FilePath, p.Name, set.entryType(p.Name), p.Status))
+		rows = append(rows, p.Rows...)
+		hierarchy.add(p.Name, set.byName[p.Name].Inherits, p.FilePath, p.Rows)
+	}
+	writeRows(expandMemberRows(rows))
+
+	return nil
+}
+
+// writeXMLIndex writes the index.html of a docset built only from class XML.
+func writeXMLIndex(sets ...*xmlClassSet) error {
+	type group struct {
+		Title   string
+		Classes []xmlClassRef
+	}
+	groups := []group{
+		{Title: "Globals"}, {Title: "Nodes"}, {Title: "Resources"}, {Title: "Other objects"}, {Title: "Variant types"},
+		{Title: "Extensions"}, {Title: "Plugins"},
+	}
+	order := map[string]int{"Global": 0, "Class": 1, "Resource": 2, "Object": 3, "Type": 4, "Extension": 5, "Plugin": 6}
+	for _, set := range sets {
+		if set == nil {
+			continue
+		}
+		for _, c := range set.classes {
+			g := &groups[order[set.entryType(c.Name)]]
+			g.Classes = append(g.Classes, xmlClassRef{Name: c.Name, Href: "classes/" + classFileName(c.Name)})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := xmlIndexTemplate.Execute(&buf, groups); err != nil {
+		return errors.Wrap(err, "failed to render index.html")
+	}
+	return writeFile(filepath.Join(targetPath, "index.html"), buf.Bytes())
+}
+
+// writeFile writes data to dest, creating the parent directories.
+func writeFile(dest string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	f, err := createFile(dest)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// createFile creates dest, replacing rather than truncating an existing file, as files of
+// the docset may be hard links shared with the docsets of other versions.
+func createFile(dest string) (*os.File, error) {
+	if err := os.Remove(dest); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return os.Create(dest)
+}
+
+const xmlPageStyle = `
+body { font-family: -apple-system, "Helvetica Neue", Arial, sans-serif; margin: 1em 2em; line-height: 1.5; }
+code, pre { font-family: Menlo, Consolas, monospace; }
+pre { background: #f4f4f4; padding: 0.5em; overflow-x: auto; }
+.classref-item { margin: 1em 0; }
+.classref-signature { font-weight: bold; }
+.classref-signature .value { font-weight: normal; }
+`
+
+var xmlClassTemplate = template.Must(template.New("class").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Name}}</title>
+<style>` + xmlPageStyle + `</style>
+{{range .Links}}<link href="{{.}}">
+{{end}}</head>
+<body>
+<section id="{{.ID}}">
+{{range .ClassTargets}}<a class="dashAnchor" name="{{.}}"></a>{{end}}<h1>{{.Name}}</h1>
+{{with .Inherits}}<p><strong>Inherits:</strong> {{range $i, $c := .}}{{if $i}} &lt; {{end}}{{if $c.Href}}<a class="reference internal" href="{{$c.Href}}">{{$c.Name}}</a>{{else}}{{$c.Name}}{{end}}{{end}}</p>
+{{end}}{{with .InheritedBy}}<p><strong>Inherited By:</strong> {{range $i, $c := .}}{{if $i}}, {{end}}<a class="reference internal" href="{{$c.Href}}">{{$c.Name}}</a>{{end}}</p>
+{{end}}{{.Note}}
+{{.Brief}}
+{{range .Sections}}<section id="{{.ID}}">
+<a class="dashAnchor" name="{{.Target}}"></a><h2>{{.Title}}</h2>
+{{.Body}}
+{{range .Items}}{{if .URL}}<p><a class="dashAnchor" name="{{.Target}}"></a><a class="reference external" href="{{.URL}}">{{.Signature}}</a></p>
+{{else}}<div class="classref-item" id="{{.ID}}">
+<a class="dashAnchor" name="{{.Target}}"></a><p class="classref-signature">{{.Signature}}{{with .Value}} <span class="value">= <code>{{.}}</code></span>{{end}}</p>
+{{with .Note}}{{.}}
+{{end}}{{.Description}}
+</div>
+{{end}}{{end}}</section>
+{{end}}</section>
+</body>
+</html>
+`))
+
+var xmlIndexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>All classes</title>
+<style>` + xmlPageStyle + `</style>
+</head>
+<body>
+<h1>All classes</h1>
+{{range .}}{{if .Classes}}<section>
+<h2>{{.Title}}</h2>
+<ul>
+{{range .Classes}}<li class="toctree-l1"><a class="reference internal" href="{{.Href}}">{{.Name}}</a></li>
+{{end}}</ul>
+</section>
+{{end}}{{end}}</body>
+</html>
+`))